@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var abortCmd = &cobra.Command{
+	Use:   "abort",
+	Short: "Abort a restack and roll back all branches",
+	Long:  "Aborts any in-flight rebase and resets every branch touched by the restack, and HEAD, back to where they were before the restack started.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !git.IsRestackInProgress() {
+			return fmt.Errorf("no restack in progress")
+		}
+
+		reset, err := stack.AbortRestack()
+		for _, b := range reset {
+			fmt.Printf("  ↺ Reset %s\n", b)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Restack aborted")
+		return nil
+	},
+}
+
+func init() {
+	abortCmd.RunE = recordOp(abortCmd.RunE)
+	rootCmd.AddCommand(abortCmd)
+}
@@ -28,11 +28,21 @@ var branchCmd = &cobra.Command{
 			return fmt.Errorf("branch %q is not tracked by st", current)
 		}
 
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			out, err := tui.RenderBranchJSON(branch, repo)
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return nil
+		}
+
 		fmt.Print(tui.RenderBranchInfo(branch, repo))
 		return nil
 	},
 }
 
 func init() {
+	branchCmd.Flags().Bool("json", false, "print branch info as JSON")
 	rootCmd.AddCommand(branchCmd)
 }
@@ -16,6 +16,9 @@ var continueCmd = &cobra.Command{
 			return fmt.Errorf("no restack in progress")
 		}
 
+		// Remember where the restack started so we can return there
+		head, _ := git.GetRestackHead()
+
 		// First, continue the git rebase
 		if git.IsRebaseInProgress() {
 			if err := git.RebaseContinue(); err != nil {
@@ -29,24 +32,23 @@ var continueCmd = &cobra.Command{
 			return err
 		}
 
-		for _, b := range result.Rebased {
-			fmt.Printf("  ✓ Rebased %s\n", b)
-		}
-		for _, b := range result.Skipped {
-			fmt.Printf("  · %s (already up to date)\n", b)
+		if printRestackResult(result) {
+			return nil
 		}
 
-		if result.Conflict != "" {
-			fmt.Printf("\n  ✗ Conflict on %s\n", result.Conflict)
-			fmt.Println("  Resolve conflicts, then run 'st continue'")
-			return nil
+		// Return to the original branch
+		if head != "" && git.BranchExists(head) {
+			_ = git.Checkout(head)
 		}
 
+		refreshStackNav(false)
+
 		fmt.Println("Restack complete")
 		return nil
 	},
 }
 
 func init() {
+	continueCmd.RunE = recordOp(continueCmd.RunE)
 	rootCmd.AddCommand(continueCmd)
 }
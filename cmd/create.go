@@ -2,25 +2,39 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/rodrigolobo/st/internal/git"
 	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/rodrigolobo/st/internal/tui"
 	"github.com/spf13/cobra"
 )
 
 var createCmd = &cobra.Command{
-	Use:   "create <branch-name>",
+	Use:   "create [branch-name]",
 	Short: "Create a new stacked branch",
-	Long:  "Creates a new branch off the current branch and tracks it in the stack.",
-	Args:  cobra.ExactArgs(1),
+	Long:  "Creates a new branch off the current branch and tracks it in the stack. Without a name, one is generated from the -m message using the st.branch-template setting (default {slug}), which can refer to {user}, {date}, {issue} and {slug}. With --insert, the new branch goes between the current branch and its children: they are moved onto it (you choose which if there are several) and restacked, and changes committed with -m go on the new branch.",
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		branchName := args[0]
-
 		trunk, err := git.GetTrunk()
 		if err != nil {
 			return err
 		}
 
+		var branchName string
+		if len(args) > 0 {
+			branchName = args[0]
+		} else {
+			message, _ := cmd.Flags().GetString("message")
+			if message == "" {
+				return fmt.Errorf("a branch name is required unless -m is given")
+			}
+			if branchName, err = generateBranchName(message); err != nil {
+				return err
+			}
+		}
+
 		current, err := git.CurrentBranch()
 		if err != nil {
 			return fmt.Errorf("could not determine current branch: %w", err)
@@ -30,6 +44,11 @@ var createCmd = &cobra.Command{
 			return fmt.Errorf("branch %q already exists", branchName)
 		}
 
+		insert, _ := cmd.Flags().GetBool("insert")
+		if insert {
+			return insertBranch(cmd, branchName, current)
+		}
+
 		// If -a flag, stage all changes
 		stageAll, _ := cmd.Flags().GetBool("all")
 		if stageAll {
@@ -61,14 +80,108 @@ var createCmd = &cobra.Command{
 		if err := stack.TrackBranch(branchName, parent); err != nil {
 			return fmt.Errorf("failed to track branch: %w", err)
 		}
+		if tip, err := git.BranchTip(branchName); err == nil {
+			if err := stack.SetBase(branchName, tip); err != nil {
+				return fmt.Errorf("failed to record base: %w", err)
+			}
+		}
 
 		fmt.Printf("Created and checked out branch %q (parent: %s)\n", branchName, parent)
 		return nil
 	},
 }
 
+// generateBranchName derives a branch name from a commit message with the
+// configured template.
+func generateBranchName(message string) (string, error) {
+	template, err := git.ConfigGetAny("st.branch-template")
+	if err != nil || template == "" {
+		template = stack.DefaultBranchTemplate
+	}
+	user, err := git.ConfigGetAny("user.email")
+	if err != nil || user == "" {
+		user, _ = git.ConfigGetAny("user.name")
+	}
+	in := stack.BranchNameInput{Message: message, User: user, Date: time.Now()}
+	return stack.GenerateBranchName(template, in, git.BranchExists, git.IsValidBranchName)
+}
+
+// insertBranch creates branchName on top of current, commits staged changes
+// to it if asked to, and moves current's children onto it.
+func insertBranch(cmd *cobra.Command, branchName, current string) error {
+	if git.IsRestackInProgress() {
+		return fmt.Errorf("a restack is in progress. Run 'st continue' or 'st abort' first")
+	}
+	repo, err := loadAndBuild()
+	if err != nil {
+		return err
+	}
+
+	children := stack.ChildrenOf(repo, current)
+	if len(children) > 1 {
+		names := make([]string, len(children))
+		for i, c := range children {
+			names[i] = c.Name
+		}
+		p := tea.NewProgram(tui.NewMultiChooserModel("Move onto "+branchName, names))
+		finalModel, err := p.Run()
+		if err != nil {
+			return fmt.Errorf("TUI error: %w", err)
+		}
+		chosen, ok := finalModel.(tui.MultiChooserModel).Chosen()
+		if !ok {
+			return nil // user quit without choosing
+		}
+		children = nil
+		for _, name := range chosen {
+			children = append(children, repo.Branches[name])
+		}
+	}
+	if err := checkNotFrozen(children...); err != nil {
+		return err
+	}
+
+	if stageAll, _ := cmd.Flags().GetBool("all"); stageAll {
+		if err := git.StageAll(); err != nil {
+			return fmt.Errorf("failed to stage changes: %w", err)
+		}
+	}
+
+	if err := git.CreateBranch(branchName); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+	branch, err := stack.InsertBranch(repo, branchName, current, children)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created and checked out branch %q (parent: %s)\n", branchName, current)
+	for _, child := range children {
+		fmt.Printf("  Reparented %s → %s\n", child.Name, branchName)
+	}
+
+	if message, _ := cmd.Flags().GetString("message"); message != "" && git.HasStagedChanges() {
+		if err := git.Commit(message); err != nil {
+			return fmt.Errorf("failed to commit: %w", err)
+		}
+		fmt.Printf("Committed changes on %s\n", branchName)
+	}
+
+	result, err := stack.RestackDescendants(branch)
+	if err != nil {
+		return err
+	}
+	if printRestackResult(result) {
+		return nil
+	}
+	_ = git.Checkout(branchName)
+	refreshStackNav(false)
+	return nil
+}
+
 func init() {
-	createCmd.Flags().StringP("message", "m", "", "commit staged changes with message before creating branch")
+	createCmd.RunE = recordOp(createCmd.RunE)
+	createCmd.Flags().StringP("message", "m", "", "commit staged changes with message before creating branch, and name the branch after it if no name is given")
 	createCmd.Flags().BoolP("all", "a", false, "stage all changes before committing")
+	createCmd.Flags().Bool("insert", false, "insert the branch between the current branch and its children")
 	rootCmd.AddCommand(createCmd)
 }
@@ -35,8 +35,15 @@ var deleteCmd = &cobra.Command{
 			return fmt.Errorf("branch %q is not tracked by st", branchName)
 		}
 
-		// Reparent children
+		// Reparent children. They keep the deleted branch's commits, so
+		// their own changes now start where the deleted branch's did.
+		base := stack.ForkPoint(repo, branch)
 		for _, child := range branch.Children {
+			if base != "" {
+				if err := stack.SetBase(child.Name, base); err != nil {
+					return fmt.Errorf("failed to record base of %s: %w", child.Name, err)
+				}
+			}
 			if err := stack.ReparentBranch(child.Name, branch.Parent); err != nil {
 				return fmt.Errorf("failed to reparent %s: %w", child.Name, err)
 			}
@@ -69,5 +76,6 @@ var deleteCmd = &cobra.Command{
 }
 
 func init() {
+	deleteCmd.RunE = recordOp(deleteCmd.RunE)
 	rootCmd.AddCommand(deleteCmd)
 }
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check stack metadata for problems",
+	Long:  "Looks for parent cycles, parents and tracked branches that no longer exist, leftover restack state, and trunk misconfiguration. With --fix, offers to repair each problem found.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		problems, err := stack.Diagnose()
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			fmt.Println("No problems found")
+			return nil
+		}
+
+		fix, _ := cmd.Flags().GetBool("fix")
+		if !fix {
+			for _, p := range problems {
+				fmt.Printf("  ✗ %s\n", p.Detail)
+			}
+			fmt.Printf("\n%d problem(s) found. Run 'st doctor --fix' to repair them.\n", len(problems))
+			return nil
+		}
+
+		// Fixing one problem can resolve or change others, so diagnose
+		// again after every fix
+		skipped := make(map[stack.Problem]bool)
+		fixed := 0
+		for {
+			var next *stack.Problem
+			for i := range problems {
+				if !skipped[problems[i]] {
+					next = &problems[i]
+					break
+				}
+			}
+			if next == nil {
+				break
+			}
+
+			fmt.Printf("  ✗ %s\n", next.Detail)
+			if !confirm(fmt.Sprintf("    Fix: %s?", next.Fix())) {
+				skipped[*next] = true
+				continue
+			}
+			if err := stack.FixProblem(*next); err != nil {
+				fmt.Printf("    Could not fix: %v\n", err)
+				skipped[*next] = true
+				continue
+			}
+			fmt.Println("    ✓ Fixed")
+			fixed++
+
+			if problems, err = stack.Diagnose(); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("\n%d problem(s) fixed, %d left\n", fixed, len(skipped))
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.RunE = recordOp(doctorCmd.RunE)
+	doctorCmd.Flags().Bool("fix", false, "offer to repair each problem found")
+	rootCmd.AddCommand(doctorCmd)
+}
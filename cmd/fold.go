@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var foldCmd = &cobra.Command{
+	Use:   "fold",
+	Short: "Fold the current branch into its parent",
+	Long:  "Moves the current branch's commits into its parent by fast-forwarding the parent (or, with --squash, adding them to it as a single commit), reparents the branch's children onto the parent, deletes the branch, and restacks everything above the parent.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if git.IsRestackInProgress() {
+			return fmt.Errorf("a restack is in progress. Run 'st continue' or 'st abort' first")
+		}
+		if git.HasUncommittedChanges() {
+			return fmt.Errorf("you have uncommitted changes. Commit or stash them first")
+		}
+
+		repo, err := loadAndBuild()
+		if err != nil {
+			return err
+		}
+
+		branch := stack.CurrentBranch(repo)
+		if branch == nil {
+			return fmt.Errorf("current branch is not tracked by st")
+		}
+		parent, ok := repo.Branches[branch.Parent]
+		if !ok {
+			return fmt.Errorf("cannot fold %s into %s: only tracked branches can be folded into", branch.Name, branch.Parent)
+		}
+		if err := checkNotFrozen(branch, parent); err != nil {
+			return err
+		}
+		if stack.NeedsRestack(repo, branch) {
+			return fmt.Errorf("branch %q needs restack. Run 'st restack' first", branch.Name)
+		}
+
+		squash, _ := cmd.Flags().GetBool("squash")
+
+		if err := stack.FoldBranch(branch, parent, squash); err != nil {
+			return err
+		}
+		for _, child := range branch.Children {
+			fmt.Printf("  Reparented %s → %s\n", child.Name, parent.Name)
+		}
+		fmt.Printf("Folded %s into %s\n", branch.Name, parent.Name)
+
+		// Reload so the tree reflects the reparented children
+		repo, err = loadAndBuild()
+		if err != nil {
+			return err
+		}
+		result, err := stack.RestackDescendants(repo.Branches[parent.Name])
+		if err != nil {
+			return err
+		}
+		if printRestackResult(result) {
+			return nil
+		}
+		if err := git.Checkout(parent.Name); err != nil {
+			return err
+		}
+		refreshStackNav(false)
+		return nil
+	},
+}
+
+func init() {
+	foldCmd.RunE = recordOp(foldCmd.RunE)
+	foldCmd.Flags().Bool("squash", false, "combine the branch's commits into a single commit on the parent")
+	rootCmd.AddCommand(foldCmd)
+}
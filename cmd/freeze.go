@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var freezeCmd = &cobra.Command{
+	Use:   "freeze [branch]",
+	Short: "Protect a branch from being rewritten or pushed",
+	Long:  "Freezes a branch (the current one by default), typically one owned by someone else. Restacks leave a frozen branch alone while still restacking the branches above it onto it, submit doesn't push it, and modify, fold, split and move refuse to rewrite it.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setFrozen(args, true)
+	},
+}
+
+var unfreezeCmd = &cobra.Command{
+	Use:   "unfreeze [branch]",
+	Short: "Allow a frozen branch to be rewritten and pushed again",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setFrozen(args, false)
+	},
+}
+
+// setFrozen freezes or unfreezes the branch named in args, or the current
+// branch.
+func setFrozen(args []string, frozen bool) error {
+	repo, err := loadAndBuild()
+	if err != nil {
+		return err
+	}
+	name, err := branchArg(args)
+	if err != nil {
+		return err
+	}
+	branch, ok := repo.Branches[name]
+	if !ok {
+		return fmt.Errorf("branch %q is not tracked by st", name)
+	}
+
+	if branch.Frozen == frozen {
+		if frozen {
+			fmt.Printf("%s is already frozen\n", name)
+		} else {
+			fmt.Printf("%s is not frozen\n", name)
+		}
+		return nil
+	}
+	if err := stack.SetFrozen(name, frozen); err != nil {
+		return fmt.Errorf("failed to update %s: %w", name, err)
+	}
+	if frozen {
+		fmt.Printf("Froze %s\n", name)
+	} else {
+		fmt.Printf("Unfroze %s\n", name)
+	}
+	return nil
+}
+
+// checkNotFrozen returns an error if any of the branches is frozen.
+func checkNotFrozen(branches ...*stack.Branch) error {
+	for _, b := range branches {
+		if b != nil && b.Frozen {
+			return fmt.Errorf("branch %q is frozen. Run 'st unfreeze %s' first", b.Name, b.Name)
+		}
+	}
+	return nil
+}
+
+func init() {
+	freezeCmd.RunE = recordOp(freezeCmd.RunE)
+	unfreezeCmd.RunE = recordOp(unfreezeCmd.RunE)
+	rootCmd.AddCommand(freezeCmd)
+	rootCmd.AddCommand(unfreezeCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/forge"
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <branch>",
+	Short: "Check out a stack from the remote",
+	Long:  "Fetches a branch and every branch below it in its stack from origin, creates local branches for them, tracks them on their parents and checks out the branch. Parents come from metadata shared with 'st metadata push', then from the base branch of each branch's pull request, and otherwise default to trunk. The walk stops at a parent with neither shared metadata nor a pull request, such as develop, which is created locally but not tracked. Branches already tracked here keep their metadata, and local branches are only fast-forwarded.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if git.IsRestackInProgress() {
+			return fmt.Errorf("a restack is in progress. Run 'st continue' or 'st abort' first")
+		}
+		if !git.HasRemote() {
+			return fmt.Errorf("no remote configured")
+		}
+
+		repo, err := loadAndBuild()
+		if err != nil {
+			return err
+		}
+		name := args[0]
+		if repo.IsTrunk(name) {
+			return fmt.Errorf("cannot get trunk branch")
+		}
+
+		fmt.Println("Fetching from origin...")
+		if err := git.Fetch("origin"); err != nil {
+			return fmt.Errorf("failed to fetch: %w", err)
+		}
+		meta, err := stack.FetchRemoteMetadata("origin")
+		if err != nil {
+			return err
+		}
+
+		f, _ := forge.Detect("origin")
+		prBase := func(head string) string {
+			if f == nil {
+				return ""
+			}
+			pr, err := f.FindPullRequest(head)
+			if err != nil || pr == nil {
+				return ""
+			}
+			return pr.Base
+		}
+		onRemote := func(branch string) bool {
+			return git.RemoteBranchExists("origin", branch)
+		}
+
+		chain, err := stack.ResolveDownstack(repo, name, meta, prBase, onRemote)
+		if err != nil {
+			return err
+		}
+		// The stack may rest on a branch outside it, such as develop. Check
+		// that one out too, but leave it untracked.
+		if base := chain[0].Parent; !repo.IsTrunk(base) && !git.BranchExists(base) {
+			if err := git.CreateBranchAt(base, "origin/"+base); err != nil {
+				return fmt.Errorf("failed to create %s: %w", base, err)
+			}
+			if err := git.SetUpstream(base, "origin"); err != nil {
+				return fmt.Errorf("failed to set upstream of %s: %w", base, err)
+			}
+			fmt.Printf("  Created %s (not tracked)\n", base)
+		}
+		for _, rb := range chain {
+			upToDate, err := stack.GetRemoteBranch(repo, "origin", rb)
+			if err != nil {
+				return err
+			}
+			if _, tracked := repo.Branches[rb.Name]; tracked {
+				fmt.Printf("  Updated %s\n", rb.Name)
+			} else {
+				fmt.Printf("  Tracked %s → %s (from %s)\n", rb.Name, rb.Parent, rb.Source)
+			}
+			if !upToDate {
+				fmt.Printf("  Warning: %s has diverged from origin/%s and was left as is\n", rb.Name, rb.Name)
+			}
+		}
+
+		if err := git.Checkout(name); err != nil {
+			return fmt.Errorf("failed to checkout %s: %w", name, err)
+		}
+		fmt.Printf("Checked out %s\n", name)
+		return nil
+	},
+}
+
+func init() {
+	getCmd.RunE = recordOp(getCmd.RunE)
+	rootCmd.AddCommand(getCmd)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/rodrigolobo/st/internal/git"
 	"github.com/spf13/cobra"
@@ -10,39 +11,50 @@ import (
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize st in the current git repository",
-	Long:  "Sets up st by detecting or specifying the trunk branch.",
+	Long:  "Sets up st by detecting or specifying the trunk branch. Repeat --trunk to configure several trunks, such as main and long-lived release branches; the first is the primary trunk.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if !git.IsInsideWorkTree() {
 			return fmt.Errorf("not inside a git repository")
 		}
 
-		trunk, _ := cmd.Flags().GetString("trunk")
+		trunks, _ := cmd.Flags().GetStringArray("trunk")
 
-		if trunk == "" {
+		if len(trunks) == 0 {
 			// Auto-detect trunk
 			if git.BranchExists("main") {
-				trunk = "main"
+				trunks = []string{"main"}
 			} else if git.BranchExists("master") {
-				trunk = "master"
+				trunks = []string{"master"}
 			} else {
 				return fmt.Errorf("could not auto-detect trunk branch. Use --trunk to specify")
 			}
 		} else {
-			if !git.BranchExists(trunk) {
-				return fmt.Errorf("branch %q does not exist", trunk)
+			for _, trunk := range trunks {
+				if !git.BranchExists(trunk) {
+					return fmt.Errorf("branch %q does not exist", trunk)
+				}
 			}
 		}
 
-		if err := git.SetTrunk(trunk); err != nil {
+		if err := git.SetTrunk(trunks[0]); err != nil {
 			return fmt.Errorf("failed to set trunk: %w", err)
 		}
+		for _, trunk := range trunks[1:] {
+			if err := git.AddTrunk(trunk); err != nil {
+				return fmt.Errorf("failed to add trunk %s: %w", trunk, err)
+			}
+		}
 
-		fmt.Printf("Initialized st with trunk branch: %s\n", trunk)
+		if len(trunks) == 1 {
+			fmt.Printf("Initialized st with trunk branch: %s\n", trunks[0])
+		} else {
+			fmt.Printf("Initialized st with trunk branches: %s\n", strings.Join(trunks, ", "))
+		}
 		return nil
 	},
 }
 
 func init() {
-	initCmd.Flags().StringP("trunk", "t", "", "trunk branch name (default: auto-detect main/master)")
+	initCmd.Flags().StringArrayP("trunk", "t", nil, "trunk branch name, repeatable (default: auto-detect main/master)")
 	rootCmd.AddCommand(initCmd)
 }
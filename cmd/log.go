@@ -12,7 +12,7 @@ var logCmd = &cobra.Command{
 	Use:     "log",
 	Aliases: []string{"ls"},
 	Short:   "Show the stack tree",
-	Long:    "Displays the tree of all stacked branches with commit counts and status.",
+	Long:    "Displays the tree of all stacked branches with commit counts and status. Use --long to also list each branch's commits, --short for one compact line per branch, --stack to show only the current stack, and --reverse to print it like a vertical stack, with trunk at the bottom.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		repo, err := stack.LoadRepo()
 		if err != nil {
@@ -20,16 +20,44 @@ var logCmd = &cobra.Command{
 		}
 		stack.BuildTree(repo)
 
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			out, err := tui.RenderTreeJSON(repo)
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return nil
+		}
+
 		if len(repo.Stacks) == 0 {
 			fmt.Println("No stacked branches found. Use 'st create <name>' to create one.")
 			return nil
 		}
 
-		fmt.Print(tui.RenderTree(repo))
+		var opts tui.LogOptions
+		if short, _ := cmd.Flags().GetBool("short"); short {
+			opts.Format = tui.LogShort
+		}
+		if long, _ := cmd.Flags().GetBool("long"); long {
+			opts.Format = tui.LogLong
+		}
+		opts.Stack, _ = cmd.Flags().GetBool("stack")
+		opts.Reverse, _ = cmd.Flags().GetBool("reverse")
+		if opts.Stack && stack.CurrentStack(repo) == nil {
+			return fmt.Errorf("current branch is not in a tracked stack")
+		}
+
+		fmt.Print(tui.RenderLog(repo, opts))
 		return nil
 	},
 }
 
 func init() {
+	logCmd.Flags().Bool("json", false, "print the stack tree as JSON")
+	logCmd.Flags().Bool("short", false, "show one compact line per branch")
+	logCmd.Flags().Bool("long", false, "list each branch's commits under it")
+	logCmd.Flags().Bool("stack", false, "show only the current stack")
+	logCmd.Flags().Bool("reverse", false, "show leaves at the top and trunk at the bottom")
+	logCmd.MarkFlagsMutuallyExclusive("short", "long")
 	rootCmd.AddCommand(logCmd)
 }
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var metadataCmd = &cobra.Command{
+	Use:   "metadata",
+	Short: "Manage where stack metadata is stored",
+	Long:  "Stack metadata (each branch's parent and base) is kept in git config by default, which is private to this clone. The refs store keeps it as blobs under refs/st/meta/ instead, which can be pushed and fetched to share stacks between machines or collaborators.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := stack.CurrentStore()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Metadata store: %s\n", store.Name())
+		return nil
+	},
+}
+
+var metadataMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move stack metadata to another store",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if git.IsRestackInProgress() {
+			return fmt.Errorf("a restack is in progress. Run 'st continue' or 'st abort' first")
+		}
+
+		to, _ := cmd.Flags().GetString("to")
+		target, err := stack.OpenStore(to)
+		if err != nil {
+			return err
+		}
+		current, err := stack.CurrentStore()
+		if err != nil {
+			return err
+		}
+		if current.Name() == target.Name() {
+			fmt.Printf("Metadata is already kept in %s\n", to)
+			return nil
+		}
+
+		n, err := stack.MigrateStore(current, target)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Moved metadata of %d branches from %s to %s\n", n, current.Name(), target.Name())
+		return nil
+	},
+}
+
+var metadataPushCmd = &cobra.Command{
+	Use:   "push [remote]",
+	Short: "Push stack metadata to a remote",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		remote := remoteArg(args)
+		if err := stack.PushMetadata(remote); err != nil {
+			return fmt.Errorf("failed to push metadata: %w", err)
+		}
+		fmt.Printf("Pushed stack metadata to %s\n", remote)
+		return nil
+	},
+}
+
+var metadataFetchCmd = &cobra.Command{
+	Use:   "fetch [remote]",
+	Short: "Fetch stack metadata from a remote",
+	Long:  "Fetches the stack metadata pushed to a remote with 'st metadata push'. Local branches the remote metadata knows about but that aren't tracked here are tracked on the same parent; branches already tracked are left alone.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		remote := remoteArg(args)
+		imported, err := stack.FetchMetadata(remote)
+		sort.Strings(imported)
+		for _, name := range imported {
+			fmt.Printf("  Tracked %s\n", name)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Fetched stack metadata from %s\n", remote)
+		return nil
+	},
+}
+
+// remoteArg returns the remote named on the command line, or origin.
+func remoteArg(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return "origin"
+}
+
+func init() {
+	metadataMigrateCmd.RunE = recordOp(metadataMigrateCmd.RunE)
+	metadataMigrateCmd.Flags().String("to", "", "target store: config or refs")
+	_ = metadataMigrateCmd.MarkFlagRequired("to")
+	metadataFetchCmd.RunE = recordOp(metadataFetchCmd.RunE)
+	metadataCmd.AddCommand(metadataMigrateCmd, metadataPushCmd, metadataFetchCmd)
+	rootCmd.AddCommand(metadataCmd)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
 	"github.com/spf13/cobra"
 )
 
@@ -11,11 +12,20 @@ var modifyCmd = &cobra.Command{
 	Use:     "modify",
 	Aliases: []string{"m"},
 	Short:   "Amend or commit changes on the current branch",
-	Long:    "By default, amends staged changes to HEAD. Use -c to create a new commit instead.",
+	Long:    "By default, amends staged changes to HEAD. Use -c to create a new commit instead. Branches stacked above the current one are restacked onto the result unless --no-restack is given.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		stageAll, _ := cmd.Flags().GetBool("all")
 		newCommit, _ := cmd.Flags().GetBool("commit")
 		message, _ := cmd.Flags().GetString("message")
+		noRestack, _ := cmd.Flags().GetBool("no-restack")
+
+		var branch *stack.Branch
+		if repo, err := loadAndBuild(); err == nil {
+			branch = stack.CurrentBranch(repo)
+		}
+		if err := checkNotFrozen(branch); err != nil {
+			return err
+		}
 
 		// Stage all if requested
 		if stageAll {
@@ -31,6 +41,17 @@ var modifyCmd = &cobra.Command{
 			}
 		}
 
+		// Pin where each child forks off before the commit moves, so the
+		// restack afterwards only replays the children's own commits
+		if noRestack || git.IsRestackInProgress() {
+			branch = nil
+		}
+		if branch != nil {
+			for _, child := range branch.Children {
+				_ = stack.EnsureBase(child.Name, branch.Name)
+			}
+		}
+
 		if newCommit {
 			// Create a new commit
 			if message == "" {
@@ -48,13 +69,33 @@ var modifyCmd = &cobra.Command{
 			fmt.Println("Amended HEAD commit")
 		}
 
+		if branch == nil || len(branch.Children) == 0 {
+			return nil
+		}
+
+		fmt.Println("Restacking descendants...")
+		result, err := stack.RestackDescendants(branch)
+		if err != nil {
+			return err
+		}
+		if printRestackResult(result) {
+			return nil
+		}
+
+		// Return to the modified branch
+		if err := git.Checkout(branch.Name); err != nil {
+			return err
+		}
+		refreshStackNav(false)
 		return nil
 	},
 }
 
 func init() {
+	modifyCmd.RunE = recordOp(modifyCmd.RunE)
 	modifyCmd.Flags().BoolP("all", "a", false, "stage all changes before committing")
 	modifyCmd.Flags().BoolP("commit", "c", false, "create a new commit instead of amending")
 	modifyCmd.Flags().StringP("message", "m", "", "commit message")
+	modifyCmd.Flags().Bool("no-restack", false, "do not restack branches above the current one")
 	rootCmd.AddCommand(modifyCmd)
 }
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move --onto <target> [branch]",
+	Short: "Move a branch and everything above it onto another branch",
+	Long:  "Makes <target> the parent of the branch (the current branch by default) and rebases the branch and its whole subtree onto it. If a rebase conflicts, resolve it and run 'st continue'.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if git.IsRestackInProgress() {
+			return fmt.Errorf("a restack is in progress. Run 'st continue' or 'st abort' first")
+		}
+
+		onto, _ := cmd.Flags().GetString("onto")
+		if onto == "" {
+			return fmt.Errorf("--onto is required")
+		}
+
+		repo, err := loadAndBuild()
+		if err != nil {
+			return err
+		}
+
+		current, err := git.CurrentBranch()
+		if err != nil {
+			return fmt.Errorf("could not determine current branch: %w", err)
+		}
+		branchName := current
+		if len(args) > 0 {
+			branchName = args[0]
+		}
+
+		branch, ok := repo.Branches[branchName]
+		if !ok {
+			return fmt.Errorf("branch %q is not tracked by st", branchName)
+		}
+		if err := checkNotFrozen(branch); err != nil {
+			return err
+		}
+		if branch.Parent == onto {
+			fmt.Printf("Branch %q is already on %q\n", branchName, onto)
+			return nil
+		}
+
+		oldParent := branch.Parent
+		if err := stack.MoveBranch(repo, branch, onto); err != nil {
+			return err
+		}
+		fmt.Printf("Moved %s: %s → %s\n", branchName, oldParent, onto)
+
+		result, err := stack.RestackSubtree(branch)
+		if err != nil {
+			return err
+		}
+		if printRestackResult(result) {
+			return nil
+		}
+
+		// Return to the original branch
+		if current != "" {
+			_ = git.Checkout(current)
+		}
+
+		// The branch may have left another stack, so update them all
+		refreshStackNav(true)
+		return nil
+	},
+}
+
+func init() {
+	moveCmd.RunE = recordOp(moveCmd.RunE)
+	moveCmd.Flags().String("onto", "", "branch to move onto")
+	rootCmd.AddCommand(moveCmd)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+// recordOp wraps a command so the branch tips and stack metadata from before
+// and after it runs are appended to the operation log, even if it fails
+// part-way through.
+func recordOp(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		before, err := stack.CaptureState()
+		if err != nil {
+			return run(cmd, args)
+		}
+
+		runErr := run(cmd, args)
+
+		after, err := stack.CaptureState()
+		if err == nil {
+			command := strings.Join(os.Args[1:], " ")
+			if err := stack.RecordOperation(command, before, after); err != nil {
+				fmt.Printf("  Warning: failed to record operation: %v\n", err)
+			}
+		}
+		return runErr
+	}
+}
+
+var oplogCmd = &cobra.Command{
+	Use:   "oplog",
+	Short: "List recorded operations",
+	Long:  "Lists the operations recorded by mutating st commands, most recent first, with the branches each one changed.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ops, err := stack.ReadOperations()
+		if err != nil {
+			return err
+		}
+		if len(ops) == 0 {
+			fmt.Println("No operations recorded")
+			return nil
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		for i := len(ops) - 1; i >= 0 && (limit <= 0 || len(ops)-i <= limit); i-- {
+			op := ops[i]
+			undoN := len(ops) - i
+			fmt.Printf("%d. #%d  %s  st %s\n", undoN, op.ID, op.Time.Local().Format("2006-01-02 15:04:05"), op.Command)
+			for _, change := range op.Changes() {
+				fmt.Printf("     %s\n", change)
+			}
+		}
+		return nil
+	},
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Revert the last n operations (default 1)",
+	Long:  "Restores every branch and parent touched by the last n recorded operations to its state from before them. Undo is itself recorded, so it can be undone.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n := 1
+		if len(args) > 0 {
+			var err error
+			n, err = strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid count: %s", args[0])
+			}
+		}
+
+		reverted, err := stack.UndoOperations(n)
+		if err != nil {
+			return err
+		}
+
+		for _, op := range reverted {
+			fmt.Printf("  ↺ Undid #%d: st %s\n", op.ID, op.Command)
+		}
+		fmt.Println("Undo complete")
+		return nil
+	},
+}
+
+func init() {
+	oplogCmd.Flags().IntP("limit", "n", 20, "maximum number of operations to show (0 for all)")
+	undoCmd.RunE = recordOp(undoCmd.RunE)
+	rootCmd.AddCommand(oplogCmd)
+	rootCmd.AddCommand(undoCmd)
+}
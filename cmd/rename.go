@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rodrigolobo/st/internal/forge"
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename [old] <new>",
+	Short: "Rename a branch, keeping its place in the stack",
+	Long:  "Renames a tracked branch (the current one by default) along with its stack metadata, and updates its children and any restack in progress to the new name. With --remote, the branch is also renamed on GitHub, which moves its pull request and retargets the pull requests of its children.",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := loadAndBuild()
+		if err != nil {
+			return err
+		}
+
+		oldName, err := branchArg(args[:len(args)-1])
+		if err != nil {
+			return err
+		}
+		newName := args[len(args)-1]
+		remote, _ := cmd.Flags().GetBool("remote")
+		if err := stack.ValidateRename(repo, oldName, remote); err != nil {
+			return err
+		}
+		branch := repo.Branches[oldName]
+		if git.BranchExists(newName) {
+			return fmt.Errorf("branch %q already exists", newName)
+		}
+
+		// The branch may have been renamed locally before, so go by its
+		// upstream to find the remote branch
+		remoteName := oldName
+		if upstream, err := git.RemoteTrackingBranch(oldName); err == nil {
+			if name, ok := strings.CutPrefix(upstream, "origin/"); ok {
+				remoteName = name
+			}
+		}
+
+		var f forge.Forge
+		if remote {
+			if !git.RemoteBranchExists("origin", remoteName) {
+				return fmt.Errorf("branch %q has not been pushed to origin", oldName)
+			}
+			if f, err = forge.Detect("origin"); err != nil {
+				return err
+			}
+		}
+
+		children := branch.Children
+		if err := stack.RenameBranch(branch, newName); err != nil {
+			return err
+		}
+		for _, child := range children {
+			fmt.Printf("  Reparented %s → %s\n", child.Name, newName)
+		}
+		fmt.Printf("Renamed %s → %s\n", oldName, newName)
+
+		if f == nil {
+			if git.RemoteBranchExists("origin", remoteName) {
+				fmt.Printf("  origin/%s keeps its old name. Use --remote to rename it too\n", remoteName)
+			}
+			return nil
+		}
+		if err := f.RenameBranch(remoteName, newName); err != nil {
+			return fmt.Errorf("failed to rename origin/%s: %w", remoteName, err)
+		}
+		if err := git.Fetch("origin"); err != nil {
+			return fmt.Errorf("failed to fetch: %w", err)
+		}
+		_ = git.DeleteRef("refs/remotes/origin/" + remoteName)
+		if err := git.SetUpstream(newName, "origin"); err != nil {
+			return fmt.Errorf("failed to set upstream of %s: %w", newName, err)
+		}
+		fmt.Printf("Renamed origin/%s → origin/%s and moved its pull requests\n", remoteName, newName)
+		return nil
+	},
+}
+
+func init() {
+	renameCmd.RunE = recordOp(renameCmd.RunE)
+	renameCmd.Flags().Bool("remote", false, "also rename the branch on GitHub, moving its pull requests")
+	rootCmd.AddCommand(renameCmd)
+}
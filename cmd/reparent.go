@@ -11,7 +11,7 @@ import (
 var reparentCmd = &cobra.Command{
 	Use:   "reparent <new-parent>",
 	Short: "Change the parent of the current branch",
-	Long:  "Changes the parent of the current branch to a new parent. Run 'st restack' afterward to rebase onto the new parent.",
+	Long:  "Changes the parent of the current branch to a new parent. Run 'st restack' afterward to rebase onto the new parent, or use 'st move' to do both in one step.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		newParent := args[0]
@@ -21,21 +21,16 @@ var reparentCmd = &cobra.Command{
 			return fmt.Errorf("could not determine current branch: %w", err)
 		}
 
-		trunk, err := git.GetTrunk()
+		// Load repo to check if current branch is tracked
+		repo, err := stack.LoadRepo()
 		if err != nil {
 			return err
 		}
 
-		if current == trunk {
+		if repo.IsTrunk(current) {
 			return fmt.Errorf("cannot reparent trunk branch")
 		}
 
-		// Load repo to check if current branch is tracked
-		repo, err := stack.LoadRepo()
-		if err != nil {
-			return err
-		}
-
 		branch, ok := repo.Branches[current]
 		if !ok {
 			return fmt.Errorf("branch %q is not tracked by st", current)
@@ -57,6 +52,12 @@ var reparentCmd = &cobra.Command{
 			return nil
 		}
 
+		// Remember where the branch's own commits start so the restack
+		// replays only those onto the new parent
+		if err := stack.EnsureBase(current, oldParent); err != nil {
+			return fmt.Errorf("failed to record base: %w", err)
+		}
+
 		if err := stack.ReparentBranch(current, newParent); err != nil {
 			return fmt.Errorf("failed to reparent: %w", err)
 		}
@@ -68,5 +69,6 @@ var reparentCmd = &cobra.Command{
 }
 
 func init() {
+	reparentCmd.RunE = recordOp(reparentCmd.RunE)
 	rootCmd.AddCommand(reparentCmd)
 }
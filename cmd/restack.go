@@ -36,17 +36,7 @@ var restackCmd = &cobra.Command{
 			return err
 		}
 
-		// Print results
-		for _, b := range result.Rebased {
-			fmt.Printf("  ✓ Rebased %s\n", b)
-		}
-		for _, b := range result.Skipped {
-			fmt.Printf("  · %s (already up to date)\n", b)
-		}
-
-		if result.Conflict != "" {
-			fmt.Printf("\n  ✗ Conflict on %s\n", result.Conflict)
-			fmt.Println("  Resolve conflicts, then run 'st continue'")
+		if printRestackResult(result) {
 			return nil
 		}
 
@@ -55,12 +45,36 @@ var restackCmd = &cobra.Command{
 			_ = git.Checkout(currentBranch)
 		}
 
+		refreshStackNav(all)
+
 		fmt.Println("Restack complete")
 		return nil
 	},
 }
 
+// printRestackResult reports what a restack did. Returns true if it stopped
+// on a conflict.
+func printRestackResult(result *stack.RestackResult) bool {
+	for _, b := range result.Rebased {
+		fmt.Printf("  ✓ Rebased %s\n", b)
+	}
+	for _, b := range result.Skipped {
+		fmt.Printf("  · %s (already up to date)\n", b)
+	}
+	for _, b := range result.Frozen {
+		fmt.Printf("  🔒 %s (frozen, left alone)\n", b)
+	}
+
+	if result.Conflict != "" {
+		fmt.Printf("\n  ✗ Conflict on %s\n", result.Conflict)
+		fmt.Println("  Resolve conflicts, then run 'st continue' (or 'st abort' to roll back)")
+		return true
+	}
+	return false
+}
+
 func init() {
+	restackCmd.RunE = recordOp(restackCmd.RunE)
 	restackCmd.Flags().Bool("all", false, "restack all stacks, not just the current one")
 	rootCmd.AddCommand(restackCmd)
 }
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/rodrigolobo/st/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split the current branch into several stacked branches",
+	Long:  "Splits the current branch at chosen commits. Each split point becomes the tip of a new tracked branch, stacked in order below the current branch, which keeps its last commits and its children. Pick the split points interactively, or pass them with --at.\n\nWith --by-hunk, the branch's whole diff against its parent is split instead: assign each hunk to a branch, and the branch is rewritten as a chain with one commit per branch whose combined diff equals the original.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if git.IsRestackInProgress() {
+			return fmt.Errorf("a restack is in progress. Run 'st continue' or 'st abort' first")
+		}
+
+		repo, err := loadAndBuild()
+		if err != nil {
+			return err
+		}
+
+		branch := stack.CurrentBranch(repo)
+		if branch == nil {
+			return fmt.Errorf("current branch is not tracked by st")
+		}
+		if err := checkNotFrozen(branch); err != nil {
+			return err
+		}
+		if stack.NeedsRestack(repo, branch) {
+			return fmt.Errorf("branch %q needs restack. Run 'st restack' first", branch.Name)
+		}
+
+		if byHunk, _ := cmd.Flags().GetBool("by-hunk"); byHunk {
+			if cmd.Flags().Changed("at") {
+				return fmt.Errorf("--at cannot be combined with --by-hunk")
+			}
+			return splitByHunk(branch)
+		}
+
+		log, err := git.ShortLog(branch.Parent, branch.Name)
+		if err != nil {
+			return fmt.Errorf("could not list commits of %s: %w", branch.Name, err)
+		}
+		commits := tui.ParseShortLog(log)
+		if len(commits) < 2 {
+			return fmt.Errorf("branch %q has fewer than two commits; nothing to split", branch.Name)
+		}
+
+		points, _ := cmd.Flags().GetStringArray("at")
+		if len(points) == 0 {
+			p := tea.NewProgram(tui.NewSplitModel(branch.Name, commits))
+			finalModel, err := p.Run()
+			if err != nil {
+				return fmt.Errorf("TUI error: %w", err)
+			}
+			points = finalModel.(tui.SplitModel).Points()
+			if len(points) == 0 {
+				return nil // user quit without splitting
+			}
+		}
+
+		names, err := stack.SplitBranch(branch, points)
+		if err != nil {
+			return err
+		}
+
+		parent := branch.Parent
+		for _, name := range names {
+			fmt.Printf("  Created %s → %s\n", name, parent)
+			parent = name
+		}
+		fmt.Printf("Split %s into %d branches\n", branch.Name, len(names)+1)
+		return nil
+	},
+}
+
+// splitByHunk lets the user assign the hunks of branch's diff to new
+// branches, rewrites it as a chain, and restacks its children.
+func splitByHunk(branch *stack.Branch) error {
+	if git.HasUncommittedChanges() {
+		return fmt.Errorf("you have uncommitted changes. Commit or stash them first")
+	}
+
+	diff, err := git.Diff(branch.Parent, branch.Name)
+	if err != nil {
+		return fmt.Errorf("could not diff %s against %s: %w", branch.Name, branch.Parent, err)
+	}
+	files := git.ParseDiff(diff)
+	if len(files) == 0 {
+		return fmt.Errorf("branch %q has no changes to split", branch.Name)
+	}
+
+	p := tea.NewProgram(tui.NewHunkSplitModel(branch.Name, files), tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("TUI error: %w", err)
+	}
+	assign := finalModel.(tui.HunkSplitModel).Assignment()
+	if assign == nil {
+		return nil // user quit without splitting
+	}
+
+	names, err := stack.SplitBranchByHunk(branch, files, assign)
+	if err != nil {
+		return err
+	}
+	parent := branch.Parent
+	for _, name := range names {
+		fmt.Printf("  Created %s → %s\n", name, parent)
+		parent = name
+	}
+	fmt.Printf("Split %s into %d branches\n", branch.Name, len(names)+1)
+
+	if len(branch.Children) == 0 {
+		return nil
+	}
+	repo, err := loadAndBuild()
+	if err != nil {
+		return err
+	}
+	result, err := stack.RestackDescendants(repo.Branches[branch.Name])
+	if err != nil {
+		return err
+	}
+	if printRestackResult(result) {
+		return nil
+	}
+	if err := git.Checkout(branch.Name); err != nil {
+		return err
+	}
+	refreshStackNav(false)
+	return nil
+}
+
+func init() {
+	splitCmd.RunE = recordOp(splitCmd.RunE)
+	splitCmd.Flags().StringArray("at", nil, "commit that ends a new branch (repeatable)")
+	splitCmd.Flags().Bool("by-hunk", false, "split the branch's diff by hunk instead of by commit")
+	rootCmd.AddCommand(splitCmd)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rodrigolobo/st/internal/forge"
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+)
+
+// stackNavMode reads where the stack navigation table is kept:
+// "body" (default), "comment" or "off".
+func stackNavMode() string {
+	mode, err := git.ConfigGet("st.stack-nav")
+	if err != nil || mode == "" {
+		return "body"
+	}
+	return mode
+}
+
+// updateStackNav upserts the stack navigation table in every open pull
+// request of the stacks rooted at roots.
+func updateStackNav(f forge.Forge, roots []*stack.Branch) error {
+	mode := stackNavMode()
+	if mode == "off" {
+		return nil
+	}
+
+	for _, root := range roots {
+		branches := stack.AllBranchesInStack(root)
+		prs := make(map[string]*forge.PullRequest)
+		for _, b := range branches {
+			pr, err := f.FindPullRequest(b.Name)
+			if err != nil {
+				return fmt.Errorf("failed to look up pull request for %s: %w", b.Name, err)
+			}
+			if pr != nil {
+				prs[b.Name] = pr
+			}
+		}
+
+		for _, b := range branches {
+			pr := prs[b.Name]
+			if pr == nil || pr.State != forge.StateOpen {
+				continue
+			}
+			section := forge.RenderStackNav(root, b.Name, prs)
+
+			var updated bool
+			var err error
+			if mode == "comment" {
+				updated, err = upsertStackComment(f, pr, section)
+			} else {
+				updated, err = upsertStackBody(f, pr, section)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update stack in PR #%d: %w", pr.Number, err)
+			}
+			if updated {
+				fmt.Printf("  ✓ Updated stack in PR #%d\n", pr.Number)
+			}
+		}
+	}
+	return nil
+}
+
+func upsertStackBody(f forge.Forge, pr *forge.PullRequest, section string) (bool, error) {
+	body := forge.UpsertStackNav(pr.Body, section)
+	if body == pr.Body {
+		return false, nil
+	}
+	pr.Body = body
+	_, err := f.UpdatePullRequest(pr)
+	return err == nil, err
+}
+
+func upsertStackComment(f forge.Forge, pr *forge.PullRequest, section string) (bool, error) {
+	comments, err := f.ListComments(pr.Number)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range comments {
+		if !strings.Contains(c.Body, forge.StackNavStart) {
+			continue
+		}
+		body := forge.UpsertStackNav(c.Body, section)
+		if body == c.Body {
+			return false, nil
+		}
+		_, err := f.UpdateComment(c.ID, body)
+		return err == nil, err
+	}
+	_, err = f.CreateComment(pr.Number, section)
+	return err == nil, err
+}
+
+// refreshStackNav updates the stack navigation in pull requests after a
+// restack when a forge is configured. Failures are reported but not fatal.
+func refreshStackNav(all bool) {
+	if !git.HasRemote() || stackNavMode() == "off" {
+		return
+	}
+	f, err := forge.Detect("origin")
+	if err != nil {
+		return
+	}
+	repo, err := loadAndBuild()
+	if err != nil {
+		return
+	}
+
+	roots := repo.Stacks
+	if !all {
+		root := stack.CurrentStack(repo)
+		if root == nil {
+			return
+		}
+		roots = []*stack.Branch{root}
+	}
+	if err := updateStackNav(f, roots); err != nil {
+		fmt.Printf("  Warning: could not update stack navigation: %v\n", err)
+	}
+}
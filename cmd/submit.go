@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/forge"
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var submitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: "Push branches and create or update their pull requests",
+	Long:  "Force-pushes (with lease) the current branch and every branch below it, then opens or updates one pull request per branch, based on its st parent. Frozen branches are neither pushed nor given pull requests. Use --stack to submit the whole stack, including branches above the current one.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if git.IsRestackInProgress() {
+			return fmt.Errorf("a restack is in progress. Run 'st continue' or 'st abort' first")
+		}
+
+		repo, err := loadAndBuild()
+		if err != nil {
+			return err
+		}
+
+		current := stack.CurrentBranch(repo)
+		if current == nil {
+			return fmt.Errorf("current branch is not tracked by st")
+		}
+
+		wholeStack, _ := cmd.Flags().GetBool("stack")
+		draft, _ := cmd.Flags().GetBool("draft")
+
+		var branches []*stack.Branch
+		if wholeStack {
+			branches = stack.AllBranchesInStack(stack.CurrentStack(repo))
+		} else {
+			branches = stack.PathToTrunk(repo, current)
+		}
+
+		for _, b := range branches {
+			if !b.Frozen && stack.NeedsRestack(repo, b) {
+				return fmt.Errorf("branch %q needs restack. Run 'st restack' first", b.Name)
+			}
+		}
+
+		f, err := forge.Detect("origin")
+		if err != nil {
+			return err
+		}
+
+		for _, b := range branches {
+			if b.Frozen {
+				fmt.Printf("Skipping %s (frozen)\n", b.Name)
+				continue
+			}
+			fmt.Printf("Pushing %s...\n", b.Name)
+			if err := git.PushForce("origin", b.Name); err != nil {
+				return fmt.Errorf("failed to push %s: %w", b.Name, err)
+			}
+			_ = git.SetUpstream(b.Name, "origin")
+
+			pr, err := submitPullRequest(f, b, draft)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("  %s\n", pr.URL)
+		}
+
+		if err := updateStackNav(f, []*stack.Branch{stack.CurrentStack(repo)}); err != nil {
+			return err
+		}
+
+		fmt.Println("Submit complete")
+		return nil
+	},
+}
+
+// submitPullRequest creates the pull request for a branch, or retargets its
+// existing one if the branch has been moved onto a different parent.
+func submitPullRequest(f forge.Forge, b *stack.Branch, draft bool) (*forge.PullRequest, error) {
+	pr, err := f.FindPullRequest(b.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up pull request for %s: %w", b.Name, err)
+	}
+
+	if pr != nil && pr.State == forge.StateOpen {
+		if pr.Base == b.Parent {
+			fmt.Printf("  · PR #%d is up to date\n", pr.Number)
+			return pr, nil
+		}
+		oldBase := pr.Base
+		pr.Base = b.Parent
+		pr, err = f.UpdatePullRequest(pr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update pull request for %s: %w", b.Name, err)
+		}
+		fmt.Printf("  ✓ Retargeted PR #%d: %s → %s\n", pr.Number, oldBase, b.Parent)
+		return pr, nil
+	}
+
+	title, body, err := git.FirstCommitMessage(b.Parent, b.Name)
+	if err != nil {
+		title = b.Name
+	}
+	pr, err = f.CreatePullRequest(&forge.PullRequest{
+		Head:  b.Name,
+		Base:  b.Parent,
+		Title: title,
+		Body:  body,
+		Draft: draft,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull request for %s: %w", b.Name, err)
+	}
+	fmt.Printf("  ✓ Created PR #%d\n", pr.Number)
+	return pr, nil
+}
+
+func init() {
+	submitCmd.Flags().Bool("stack", false, "submit the whole stack, including branches above the current one")
+	submitCmd.Flags().Bool("draft", false, "open new pull requests as drafts")
+	rootCmd.AddCommand(submitCmd)
+}
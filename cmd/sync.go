@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 
+	"github.com/rodrigolobo/st/internal/forge"
 	"github.com/rodrigolobo/st/internal/git"
 	"github.com/rodrigolobo/st/internal/stack"
 	"github.com/spf13/cobra"
@@ -11,7 +13,7 @@ import (
 var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Sync with remote and restack",
-	Long:  "Fetches from remote, fast-forwards trunk, cleans merged branches, and restacks all stacks.",
+	Long:  "Fetches from remote, fast-forwards every trunk, cleans branches merged into their stack's trunk (including squash and rebase merges), and restacks all stacks.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		repo, err := loadAndBuild()
 		if err != nil {
@@ -28,35 +30,72 @@ var syncCmd = &cobra.Command{
 				return fmt.Errorf("failed to fetch: %w", err)
 			}
 
-			// Fast-forward trunk
-			fmt.Printf("Updating %s...\n", repo.Trunk)
-			if err := git.FastForward(repo.Trunk, "origin"); err != nil {
-				fmt.Printf("  Warning: could not fast-forward %s: %v\n", repo.Trunk, err)
+			// Fast-forward every trunk
+			for _, trunk := range repo.Trunks {
+				fmt.Printf("Updating %s...\n", trunk)
+				if err := git.FastForward(trunk, "origin"); err != nil {
+					fmt.Printf("  Warning: could not fast-forward %s: %v\n", trunk, err)
+				}
+			}
+		}
+
+		// Detect branches merged into their stack's trunk, including squash
+		// and rebase merges. If a forge is configured, a merged pull request
+		// also counts, as long as the branch hasn't moved since it was pushed.
+		f, _ := forge.Detect("origin")
+		names := make([]string, 0, len(repo.Branches))
+		for name := range repo.Branches {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		merged := make(map[string]bool)
+		for _, name := range names {
+			b := repo.Branches[name]
+			if stack.IsMerged(repo, b, repo.TrunkOf(b)) || isPullRequestMerged(f, name) {
+				merged[name] = true
 			}
 		}
 
-		// Detect and clean merged branches
-		var merged []string
-		for name, branch := range repo.Branches {
-			if git.IsBranchMergedInto(name, repo.Trunk) {
-				merged = append(merged, name)
-				// Reparent children first
-				for _, child := range branch.Children {
-					if err := stack.ReparentBranch(child.Name, branch.Parent); err != nil {
-						fmt.Printf("  Warning: failed to reparent %s: %v\n", child.Name, err)
-					}
+		// Move children of merged branches onto the nearest unmerged
+		// ancestor. Record where each child's own commits start so the
+		// restack replays only those, not the merged parent's.
+		for _, name := range names {
+			if !merged[name] {
+				continue
+			}
+			branch := repo.Branches[name]
+			newParent := branch.Parent
+			for merged[newParent] {
+				newParent = repo.Branches[newParent].Parent
+			}
+			for _, child := range branch.Children {
+				if merged[child.Name] {
+					continue
+				}
+				if err := stack.EnsureBase(child.Name, name); err != nil {
+					fmt.Printf("  Warning: failed to record base of %s: %v\n", child.Name, err)
 				}
+				if err := stack.ReparentBranch(child.Name, newParent); err != nil {
+					fmt.Printf("  Warning: failed to reparent %s: %v\n", child.Name, err)
+					continue
+				}
+				fmt.Printf("  Reparented %s → %s\n", child.Name, newParent)
 			}
 		}
 
-		for _, name := range merged {
+		for _, name := range names {
+			if !merged[name] {
+				continue
+			}
 			if name == currentBranch {
 				// Switch to trunk before deleting current branch
-				if err := git.Checkout(repo.Trunk); err != nil {
+				trunk := repo.TrunkOf(repo.Branches[name])
+				if err := git.Checkout(trunk); err != nil {
 					fmt.Printf("  Warning: could not switch to trunk: %v\n", err)
 					continue
 				}
-				currentBranch = repo.Trunk
+				currentBranch = trunk
 			}
 			if err := stack.UntrackBranch(name); err != nil {
 				fmt.Printf("  Warning: failed to untrack %s: %v\n", name, err)
@@ -85,29 +124,44 @@ var syncCmd = &cobra.Command{
 			return err
 		}
 
-		for _, b := range result.Rebased {
-			fmt.Printf("  ✓ Rebased %s\n", b)
-		}
-		for _, b := range result.Skipped {
-			fmt.Printf("  · %s (up to date)\n", b)
-		}
-
-		if result.Conflict != "" {
-			fmt.Printf("\n  ✗ Conflict on %s\n", result.Conflict)
-			fmt.Println("  Resolve conflicts, then run 'st continue'")
+		if printRestackResult(result) {
 			return nil
 		}
 
 		// Return to original branch if it still exists
-		if currentBranch != "" && currentBranch != repo.Trunk && git.BranchExists(currentBranch) {
+		if currentBranch != "" && !repo.IsTrunk(currentBranch) && git.BranchExists(currentBranch) {
 			_ = git.Checkout(currentBranch)
 		}
 
+		refreshStackNav(true)
+
 		fmt.Println("Sync complete")
 		return nil
 	},
 }
 
+// isPullRequestMerged reports whether the branch's pull request was merged
+// and the local branch still matches what was pushed for it.
+func isPullRequestMerged(f forge.Forge, branch string) bool {
+	if f == nil {
+		return false
+	}
+	pr, err := f.FindPullRequest(branch)
+	if err != nil || pr == nil || pr.State != forge.StateMerged {
+		return false
+	}
+	tip, err := git.BranchTip(branch)
+	if err != nil {
+		return false
+	}
+	if pr.HeadSHA != tip {
+		fmt.Printf("  Warning: PR #%d for %s was merged, but the branch has changed since. Keeping it\n", pr.Number, branch)
+		return false
+	}
+	return true
+}
+
 func init() {
+	syncCmd.RunE = recordOp(syncCmd.RunE)
 	rootCmd.AddCommand(syncCmd)
 }
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/rodrigolobo/st/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var trackCmd = &cobra.Command{
+	Use:   "track [branch]",
+	Short: "Start tracking an existing branch",
+	Long:  "Adds an existing git branch (the current one by default) to a stack. The parent is inferred as the tracked branch or trunk the branch is closest to, with a chooser if that is ambiguous, unless --parent is given. With --recursive, untracked branches it is stacked on are adopted too.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := loadAndBuild()
+		if err != nil {
+			return err
+		}
+
+		name, err := branchArg(args)
+		if err != nil {
+			return err
+		}
+		if !git.BranchExists(name) {
+			return fmt.Errorf("branch %q does not exist", name)
+		}
+		if repo.IsTrunk(name) {
+			return fmt.Errorf("cannot track trunk branch")
+		}
+		if b, ok := repo.Branches[name]; ok {
+			return fmt.Errorf("branch %q is already tracked on %s. Use 'st move' to change its parent", name, b.Parent)
+		}
+
+		parent, _ := cmd.Flags().GetString("parent")
+		if parent == "" {
+			recursive, _ := cmd.Flags().GetBool("recursive")
+			return trackInferred(repo, name, recursive)
+		}
+
+		if err := stack.ValidateParent(repo, name, parent); err != nil {
+			return err
+		}
+		if !git.BranchExists(parent) {
+			return fmt.Errorf("branch %q does not exist", parent)
+		}
+		if err := stack.AdoptBranch(name, parent); err != nil {
+			return fmt.Errorf("failed to track %s: %w", name, err)
+		}
+		fmt.Printf("Tracked %s → %s\n", name, parent)
+		return nil
+	},
+}
+
+// trackInferred tracks name on its inferred parent, asking the user to
+// choose if more than one branch is equally close. If recursive is set and
+// the parent is itself untracked, it is tracked first.
+func trackInferred(repo *stack.Repo, name string, recursive bool) error {
+	candidates, err := stack.InferParent(repo, name, recursive)
+	if err != nil {
+		return err
+	}
+
+	parent := candidates[0]
+	if len(candidates) > 1 {
+		p := tea.NewProgram(tui.NewChooserModel("Parent of "+name, candidates))
+		finalModel, err := p.Run()
+		if err != nil {
+			return fmt.Errorf("TUI error: %w", err)
+		}
+		if parent = finalModel.(tui.ChooserModel).Chosen(); parent == "" {
+			return nil // user quit without choosing
+		}
+	}
+
+	if _, tracked := repo.Branches[parent]; !tracked && !repo.IsTrunk(parent) && recursive {
+		if err := trackInferred(repo, parent, recursive); err != nil {
+			return err
+		}
+	}
+
+	if err := stack.AdoptBranch(name, parent); err != nil {
+		return fmt.Errorf("failed to track %s: %w", name, err)
+	}
+	repo.Branches[name] = &stack.Branch{Name: name, Parent: parent}
+	fmt.Printf("Tracked %s → %s\n", name, parent)
+	return nil
+}
+
+var untrackCmd = &cobra.Command{
+	Use:   "untrack [branch]",
+	Short: "Stop tracking a branch without deleting it",
+	Long:  "Removes a branch (the current one by default) from its stack, leaving the git branch intact. Its children are moved onto its parent and keep its commits.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := loadAndBuild()
+		if err != nil {
+			return err
+		}
+
+		name, err := branchArg(args)
+		if err != nil {
+			return err
+		}
+		branch, ok := repo.Branches[name]
+		if !ok {
+			return fmt.Errorf("branch %q is not tracked by st", name)
+		}
+
+		for _, child := range branch.Children {
+			fmt.Printf("  Reparented %s → %s\n", child.Name, branch.Parent)
+		}
+		if err := stack.ReleaseBranch(repo, branch); err != nil {
+			return err
+		}
+		fmt.Printf("Untracked %s\n", name)
+		return nil
+	},
+}
+
+// branchArg returns the branch named on the command line, or the current
+// branch if none was given.
+func branchArg(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	current, err := git.CurrentBranch()
+	if err != nil {
+		return "", fmt.Errorf("could not determine current branch: %w", err)
+	}
+	return current, nil
+}
+
+func init() {
+	trackCmd.RunE = recordOp(trackCmd.RunE)
+	trackCmd.Flags().StringP("parent", "p", "", "parent branch (default: inferred)")
+	trackCmd.Flags().BoolP("recursive", "r", false, "also track untracked branches this one is stacked on")
+	untrackCmd.RunE = recordOp(untrackCmd.RunE)
+	rootCmd.AddCommand(trackCmd)
+	rootCmd.AddCommand(untrackCmd)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var trunkCmd = &cobra.Command{
+	Use:   "trunk",
+	Short: "Manage the trunk branches stacks are built on",
+	Long:  "Lists the configured trunks. Besides the primary trunk, long-lived branches such as release/* can be made trunks too, so stacks can be built on them, restacked onto them and cleaned up once merged into them.",
+	Args:  cobra.NoArgs,
+	RunE:  listTrunks,
+}
+
+var trunkListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the trunk branches",
+	Args:  cobra.NoArgs,
+	RunE:  listTrunks,
+}
+
+var trunkAddCmd = &cobra.Command{
+	Use:   "add <branch>",
+	Short: "Make a branch a trunk",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		repo, err := stack.LoadRepo()
+		if err != nil {
+			return err
+		}
+		if !git.BranchExists(name) {
+			return fmt.Errorf("branch %q does not exist", name)
+		}
+		if repo.IsTrunk(name) {
+			return fmt.Errorf("%s is already a trunk", name)
+		}
+		if b, ok := repo.Branches[name]; ok {
+			return fmt.Errorf("%s is tracked on %s. Run 'st untrack %s' first", name, b.Parent, name)
+		}
+		if err := git.AddTrunk(name); err != nil {
+			return fmt.Errorf("failed to add trunk: %w", err)
+		}
+		fmt.Printf("Added trunk %s\n", name)
+		return nil
+	},
+}
+
+var trunkRemoveCmd = &cobra.Command{
+	Use:   "remove <branch>",
+	Short: "Stop treating a branch as a trunk",
+	Long:  "Removes a branch from the trunks. The branch itself is kept, and stacks built on it stay where they are. Removing the primary trunk makes the next one primary.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		repo, err := loadAndBuild()
+		if err != nil {
+			return err
+		}
+		if !repo.IsTrunk(name) {
+			return fmt.Errorf("%s is not a trunk", name)
+		}
+		if len(repo.Trunks) == 1 {
+			return fmt.Errorf("cannot remove the only trunk. Run 'st init --trunk <branch>' to replace it")
+		}
+		if err := git.RemoveTrunk(name); err != nil {
+			return fmt.Errorf("failed to remove trunk: %w", err)
+		}
+		fmt.Printf("Removed trunk %s\n", name)
+
+		var stacks []string
+		for _, root := range repo.Stacks {
+			if root.Parent == name {
+				stacks = append(stacks, root.Name)
+			}
+		}
+		if len(stacks) > 0 {
+			fmt.Printf("  Still built on %s: %s. Use 'st move' to move them onto a trunk\n", name, strings.Join(stacks, ", "))
+		}
+		return nil
+	},
+}
+
+func listTrunks(cmd *cobra.Command, args []string) error {
+	trunks, err := git.GetTrunks()
+	if err != nil {
+		return err
+	}
+	for i, trunk := range trunks {
+		if i == 0 {
+			fmt.Printf("%s (primary)\n", trunk)
+		} else {
+			fmt.Println(trunk)
+		}
+	}
+	return nil
+}
+
+func init() {
+	trunkCmd.AddCommand(trunkListCmd, trunkAddCmd, trunkRemoveCmd)
+	rootCmd.AddCommand(trunkCmd)
+}
@@ -0,0 +1,106 @@
+package forge
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// Pull request states.
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+	StateMerged = "merged"
+)
+
+// PullRequest is a pull request on a forge.
+type PullRequest struct {
+	Number  int
+	URL     string
+	Head    string
+	HeadSHA string // commit the head branch pointed at when last pushed
+	Base    string
+	Title   string
+	Body    string
+	State   string // StateOpen, StateClosed or StateMerged
+	Draft   bool
+}
+
+// Comment is a discussion comment on a pull request.
+type Comment struct {
+	ID   int64
+	Body string
+}
+
+// Forge is a code hosting service that stacks can be submitted to.
+type Forge interface {
+	// FindPullRequest returns the most relevant pull request for a head
+	// branch (the open one if any, otherwise the newest), or nil if none exist.
+	FindPullRequest(head string) (*PullRequest, error)
+	// CreatePullRequest opens a new pull request from pr.Head into pr.Base.
+	CreatePullRequest(pr *PullRequest) (*PullRequest, error)
+	// UpdatePullRequest updates the base, title and body of pull request pr.Number.
+	UpdatePullRequest(pr *PullRequest) (*PullRequest, error)
+	// ListComments returns the discussion comments on pull request number.
+	ListComments(number int) ([]*Comment, error)
+	// CreateComment adds a discussion comment to pull request number.
+	CreateComment(number int, body string) (*Comment, error)
+	// UpdateComment replaces the body of an existing comment.
+	UpdateComment(id int64, body string) (*Comment, error)
+	// RenameBranch renames a branch on the forge, moving the head and base
+	// of its open pull requests along with it.
+	RenameBranch(oldName, newName string) error
+}
+
+var githubRemote = regexp.MustCompile(`^(?:https?://|ssh://)?(?:[^@/]+@)?github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseGitHubRemote extracts the owner and repository name from a GitHub remote URL.
+func ParseGitHubRemote(url string) (owner, repo string, ok bool) {
+	m := githubRemote.FindStringSubmatch(strings.TrimSpace(url))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// Detect returns the forge hosting the given remote.
+// The API token is read from GITHUB_TOKEN or GH_TOKEN. For GitHub Enterprise
+// or mirrored remotes, st.github-repo (owner/repo) and st.github-api-url
+// override what is inferred from the remote URL.
+func Detect(remote string) (Forge, error) {
+	var owner, repo string
+	if slug, err := git.ConfigGet("st.github-repo"); err == nil && slug != "" {
+		parts := strings.SplitN(slug, "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid st.github-repo %q, expected owner/repo", slug)
+		}
+		owner, repo = parts[0], parts[1]
+	} else {
+		url, err := git.RemoteURL(remote)
+		if err != nil {
+			return nil, fmt.Errorf("could not read URL of remote %q: %w", remote, err)
+		}
+		var ok bool
+		owner, repo, ok = ParseGitHubRemote(url)
+		if !ok {
+			return nil, fmt.Errorf("remote %q (%s) is not a supported forge. Set st.github-repo to owner/repo to override", remote, url)
+		}
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no GitHub token found. Set GITHUB_TOKEN or GH_TOKEN")
+	}
+
+	gh := NewGitHub(owner, repo, token)
+	if apiURL, err := git.ConfigGet("st.github-api-url"); err == nil && apiURL != "" {
+		gh.BaseURL = strings.TrimRight(apiURL, "/")
+	}
+	return gh, nil
+}
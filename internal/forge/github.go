@@ -0,0 +1,219 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// GitHub talks to the GitHub REST API.
+type GitHub struct {
+	BaseURL string // API root, e.g. https://api.github.com
+	Owner   string
+	Repo    string
+	Token   string
+	Client  *http.Client
+}
+
+// NewGitHub creates a GitHub forge for owner/repo.
+func NewGitHub(owner, repo, token string) *GitHub {
+	return &GitHub{
+		BaseURL: "https://api.github.com",
+		Owner:   owner,
+		Repo:    repo,
+		Token:   token,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type githubPull struct {
+	Number   int     `json:"number"`
+	HTMLURL  string  `json:"html_url"`
+	Title    string  `json:"title"`
+	Body     string  `json:"body"`
+	State    string  `json:"state"`
+	Draft    bool    `json:"draft"`
+	MergedAt *string `json:"merged_at"`
+	Head     struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (p *githubPull) toPullRequest() *PullRequest {
+	state := p.State
+	if p.MergedAt != nil {
+		state = StateMerged
+	}
+	return &PullRequest{
+		Number:  p.Number,
+		URL:     p.HTMLURL,
+		Head:    p.Head.Ref,
+		HeadSHA: p.Head.SHA,
+		Base:    p.Base.Ref,
+		Title:   p.Title,
+		Body:    p.Body,
+		State:   state,
+		Draft:   p.Draft,
+	}
+}
+
+// FindPullRequest returns the open pull request for head, or the newest one if none is open.
+func (g *GitHub) FindPullRequest(head string) (*PullRequest, error) {
+	query := url.Values{}
+	query.Set("head", g.Owner+":"+head)
+	query.Set("state", "all")
+	query.Set("per_page", "100")
+
+	var pulls []githubPull
+	if err := g.do("GET", g.repoPath("/pulls")+"?"+query.Encode(), nil, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+	for _, p := range pulls {
+		if p.State == StateOpen {
+			return p.toPullRequest(), nil
+		}
+	}
+	return pulls[0].toPullRequest(), nil
+}
+
+// CreatePullRequest opens a new pull request.
+func (g *GitHub) CreatePullRequest(pr *PullRequest) (*PullRequest, error) {
+	req := map[string]any{
+		"head":  pr.Head,
+		"base":  pr.Base,
+		"title": pr.Title,
+		"body":  pr.Body,
+		"draft": pr.Draft,
+	}
+	var created githubPull
+	if err := g.do("POST", g.repoPath("/pulls"), req, &created); err != nil {
+		return nil, err
+	}
+	return created.toPullRequest(), nil
+}
+
+// UpdatePullRequest updates the base, title and body of an existing pull request.
+func (g *GitHub) UpdatePullRequest(pr *PullRequest) (*PullRequest, error) {
+	req := map[string]any{
+		"base":  pr.Base,
+		"title": pr.Title,
+		"body":  pr.Body,
+	}
+	var updated githubPull
+	if err := g.do("PATCH", g.repoPath(fmt.Sprintf("/pulls/%d", pr.Number)), req, &updated); err != nil {
+		return nil, err
+	}
+	return updated.toPullRequest(), nil
+}
+
+type githubComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// ListComments returns the issue comments on a pull request.
+func (g *GitHub) ListComments(number int) ([]*Comment, error) {
+	var comments []githubComment
+	if err := g.do("GET", g.repoPath(fmt.Sprintf("/issues/%d/comments?per_page=100", number)), nil, &comments); err != nil {
+		return nil, err
+	}
+	result := make([]*Comment, len(comments))
+	for i, c := range comments {
+		result[i] = &Comment{ID: c.ID, Body: c.Body}
+	}
+	return result, nil
+}
+
+// CreateComment adds an issue comment to a pull request.
+func (g *GitHub) CreateComment(number int, body string) (*Comment, error) {
+	var created githubComment
+	if err := g.do("POST", g.repoPath(fmt.Sprintf("/issues/%d/comments", number)), map[string]any{"body": body}, &created); err != nil {
+		return nil, err
+	}
+	return &Comment{ID: created.ID, Body: created.Body}, nil
+}
+
+// UpdateComment replaces the body of an issue comment.
+func (g *GitHub) UpdateComment(id int64, body string) (*Comment, error) {
+	var updated githubComment
+	if err := g.do("PATCH", g.repoPath(fmt.Sprintf("/issues/comments/%d", id)), map[string]any{"body": body}, &updated); err != nil {
+		return nil, err
+	}
+	return &Comment{ID: updated.ID, Body: updated.Body}, nil
+}
+
+// RenameBranch renames a branch. GitHub retargets open pull requests from
+// and into it.
+func (g *GitHub) RenameBranch(oldName, newName string) error {
+	path := g.repoPath("/branches/" + url.PathEscape(oldName) + "/rename")
+	return g.do("POST", path, map[string]any{"new_name": newName}, nil)
+}
+
+func (g *GitHub) repoPath(path string) string {
+	return fmt.Sprintf("/repos/%s/%s%s", url.PathEscape(g.Owner), url.PathEscape(g.Repo), path)
+}
+
+// do sends a request to the API and decodes the JSON response into out.
+func (g *GitHub) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, g.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("github: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("github: %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return fmt.Errorf("github: %s %s: %s (%d)", method, path, apiErr.Message, resp.StatusCode)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("github: %s %s: invalid response: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub is a minimal in-memory stand-in for the GitHub pulls and comments API.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	pulls    []map[string]any
+	comments []map[string]any
+	auth     []string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	if strings.HasPrefix(r.URL.Path, "/repos/octo/widgets/issues/") {
+		f.serveComments(w, r)
+		return
+	}
+	if branch, ok := strings.CutPrefix(r.URL.Path, "/repos/octo/widgets/branches/"); ok && r.Method == "POST" {
+		f.renameBranch(w, r, strings.TrimSuffix(branch, "/rename"))
+		return
+	}
+
+	const prefix = "/repos/octo/widgets/pulls"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, prefix)
+
+	switch {
+	case r.Method == "GET" && rest == "":
+		head := r.URL.Query().Get("head")
+		var out []map[string]any
+		for i := len(f.pulls) - 1; i >= 0; i-- {
+			p := f.pulls[i]
+			if "octo:"+p["head"].(map[string]any)["ref"].(string) == head {
+				out = append(out, p)
+			}
+		}
+		writeJSON(w, http.StatusOK, out)
+
+	case r.Method == "POST" && rest == "":
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["base"] == "" {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"message": "Validation Failed"})
+			return
+		}
+		n := len(f.pulls) + 1
+		p := map[string]any{
+			"number":   n,
+			"html_url": fmt.Sprintf("https://github.com/octo/widgets/pull/%d", n),
+			"title":    req["title"],
+			"body":     req["body"],
+			"state":    "open",
+			"draft":    req["draft"],
+			"head":     map[string]any{"ref": req["head"]},
+			"base":     map[string]any{"ref": req["base"]},
+		}
+		f.pulls = append(f.pulls, p)
+		writeJSON(w, http.StatusCreated, p)
+
+	case r.Method == "PATCH":
+		n, err := strconv.Atoi(strings.TrimPrefix(rest, "/"))
+		if err != nil || n < 1 || n > len(f.pulls) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"message": "Not Found"})
+			return
+		}
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		p := f.pulls[n-1]
+		p["base"] = map[string]any{"ref": req["base"]}
+		p["title"] = req["title"]
+		p["body"] = req["body"]
+		writeJSON(w, http.StatusOK, p)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeGitHub) serveComments(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/repos/octo/widgets/issues/"), "/")
+	var req map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	switch {
+	case r.Method == "GET" && len(parts) == 2:
+		out := []map[string]any{}
+		for _, c := range f.comments {
+			if c["issue"] == parts[0] {
+				out = append(out, c)
+			}
+		}
+		writeJSON(w, http.StatusOK, out)
+	case r.Method == "POST" && len(parts) == 2:
+		c := map[string]any{"id": len(f.comments) + 1, "issue": parts[0], "body": req["body"]}
+		f.comments = append(f.comments, c)
+		writeJSON(w, http.StatusCreated, c)
+	case r.Method == "PATCH" && parts[0] == "comments":
+		id, _ := strconv.Atoi(parts[1])
+		c := f.comments[id-1]
+		c["body"] = req["body"]
+		writeJSON(w, http.StatusOK, c)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// renameBranch renames a branch, retargeting the open pull requests from and
+// into it like GitHub does.
+func (f *fakeGitHub) renameBranch(w http.ResponseWriter, r *http.Request, branch string) {
+	var req map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	for _, p := range f.pulls {
+		if p["state"] != "open" {
+			continue
+		}
+		for _, side := range []string{"head", "base"} {
+			if ref := p[side].(map[string]any); ref["ref"] == branch {
+				ref["ref"] = req["new_name"]
+			}
+		}
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"name": req["new_name"]})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestGitHub(t *testing.T) (*GitHub, *fakeGitHub) {
+	t.Helper()
+	fake := &fakeGitHub{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	gh := NewGitHub("octo", "widgets", "secret")
+	gh.BaseURL = srv.URL
+	gh.Client = srv.Client()
+	return gh, fake
+}
+
+func TestGitHub_CreateAndFind(t *testing.T) {
+	gh, fake := newTestGitHub(t)
+
+	pr, err := gh.FindPullRequest("feat-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pr != nil {
+		t.Fatalf("expected no pull request, got #%d", pr.Number)
+	}
+
+	created, err := gh.CreatePullRequest(&PullRequest{Head: "feat-a", Base: "main", Title: "Add A", Draft: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Number != 1 || created.Base != "main" || created.State != StateOpen || !created.Draft {
+		t.Errorf("unexpected pull request: %+v", created)
+	}
+
+	found, err := gh.FindPullRequest("feat-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found == nil || found.Number != 1 || found.Head != "feat-a" {
+		t.Errorf("expected to find PR #1, got %+v", found)
+	}
+
+	if fake.auth[0] != "Bearer secret" {
+		t.Errorf("expected bearer token, got %q", fake.auth[0])
+	}
+}
+
+func TestGitHub_UpdateBase(t *testing.T) {
+	gh, _ := newTestGitHub(t)
+
+	created, err := gh.CreatePullRequest(&PullRequest{Head: "feat-b", Base: "feat-a", Title: "Add B"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	created.Base = "main"
+	updated, err := gh.UpdatePullRequest(created)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Base != "main" || updated.Title != "Add B" {
+		t.Errorf("unexpected pull request after update: %+v", updated)
+	}
+}
+
+func TestGitHub_FindPrefersOpen(t *testing.T) {
+	gh, fake := newTestGitHub(t)
+
+	if _, err := gh.CreatePullRequest(&PullRequest{Head: "feat-a", Base: "main", Title: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gh.CreatePullRequest(&PullRequest{Head: "feat-a", Base: "main", Title: "second"}); err != nil {
+		t.Fatal(err)
+	}
+	// The newest is closed, the older one is still open
+	fake.pulls[1]["state"] = "closed"
+
+	found, err := gh.FindPullRequest("feat-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.Number != 1 {
+		t.Errorf("expected open PR #1, got #%d", found.Number)
+	}
+}
+
+func TestGitHub_MergedState(t *testing.T) {
+	gh, fake := newTestGitHub(t)
+
+	if _, err := gh.CreatePullRequest(&PullRequest{Head: "feat-a", Base: "main", Title: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	fake.pulls[0]["state"] = "closed"
+	fake.pulls[0]["merged_at"] = "2024-01-01T00:00:00Z"
+
+	found, err := gh.FindPullRequest("feat-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.State != StateMerged {
+		t.Errorf("expected merged, got %s", found.State)
+	}
+}
+
+func TestGitHub_APIError(t *testing.T) {
+	gh, _ := newTestGitHub(t)
+
+	_, err := gh.CreatePullRequest(&PullRequest{Head: "feat-a", Base: "", Title: "A"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "Validation Failed") || !strings.Contains(err.Error(), "422") {
+		t.Errorf("error should include API message and status, got: %v", err)
+	}
+}
+
+func TestGitHub_Comments(t *testing.T) {
+	gh, _ := newTestGitHub(t)
+
+	created, err := gh.CreateComment(4, "hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := gh.UpdateComment(created.ID, "updated"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	comments, err := gh.ListComments(4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(comments) != 1 || comments[0].Body != "updated" {
+		t.Errorf("unexpected comments: %+v", comments)
+	}
+	if others, _ := gh.ListComments(5); len(others) != 0 {
+		t.Errorf("expected no comments on another PR, got %d", len(others))
+	}
+}
+
+func TestGitHub_RenameBranch(t *testing.T) {
+	gh, fake := newTestGitHub(t)
+	if _, err := gh.CreatePullRequest(&PullRequest{Head: "old", Base: "main", Title: "Old"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gh.CreatePullRequest(&PullRequest{Head: "child", Base: "old", Title: "Child"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gh.RenameBranch("old", "new"); err != nil {
+		t.Fatalf("RenameBranch: %v", err)
+	}
+	pr, err := gh.FindPullRequest("new")
+	if err != nil || pr == nil || pr.Title != "Old" {
+		t.Fatalf("expected the pull request to follow the rename, got %+v, %v", pr, err)
+	}
+	child, _ := gh.FindPullRequest("child")
+	if child.Base != "new" {
+		t.Errorf("child base = %q, want new", child.Base)
+	}
+	if got := fake.auth[len(fake.auth)-1]; got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+}
+
+func TestParseGitHubRemote(t *testing.T) {
+	tests := []struct {
+		url   string
+		owner string
+		repo  string
+		ok    bool
+	}{
+		{"git@github.com:octo/widgets.git", "octo", "widgets", true},
+		{"git@github.com:octo/widgets", "octo", "widgets", true},
+		{"https://github.com/octo/widgets.git", "octo", "widgets", true},
+		{"https://github.com/octo/widgets", "octo", "widgets", true},
+		{"https://user@github.com/octo/widgets.git", "octo", "widgets", true},
+		{"ssh://git@github.com/octo/my.repo.git", "octo", "my.repo", true},
+		{"https://gitlab.com/octo/widgets.git", "", "", false},
+		{"/tmp/local/repo", "", "", false},
+	}
+	for _, tt := range tests {
+		owner, repo, ok := ParseGitHubRemote(tt.url)
+		if owner != tt.owner || repo != tt.repo || ok != tt.ok {
+			t.Errorf("ParseGitHubRemote(%q) = %q, %q, %v; want %q, %q, %v", tt.url, owner, repo, ok, tt.owner, tt.repo, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package forge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rodrigolobo/st/internal/stack"
+)
+
+// Markers delimiting the stack navigation section st maintains in pull
+// request descriptions and comments.
+const (
+	StackNavStart = "<!-- st:stack:start -->"
+	StackNavEnd   = "<!-- st:stack:end -->"
+)
+
+// RenderStackNav renders a markdown table of the stack rooted at root, from
+// its trunk down to the leaves, highlighting the branch named current.
+func RenderStackNav(root *stack.Branch, current string, prs map[string]*PullRequest) string {
+	var sb strings.Builder
+	sb.WriteString(StackNavStart + "\n")
+	sb.WriteString("### Stack\n\n")
+	sb.WriteString("| | PR | Branch | Status |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	sb.WriteString(fmt.Sprintf("| | | `%s` | trunk |\n", root.Parent))
+
+	var walk func(b *stack.Branch, depth int)
+	walk = func(b *stack.Branch, depth int) {
+		marker := ""
+		if b.Name == current {
+			marker = "👉"
+		}
+
+		number := "—"
+		status := "not submitted"
+		if pr := prs[b.Name]; pr != nil {
+			number = fmt.Sprintf("#%d", pr.Number)
+			status = pr.State
+			if pr.State == StateOpen && pr.Draft {
+				status = "draft"
+			}
+		}
+
+		name := fmt.Sprintf("`%s`", b.Name)
+		if b.Name == current {
+			name = "**" + name + "**"
+		}
+		indent := strings.Repeat("&nbsp;&nbsp;", depth)
+		if depth > 0 {
+			indent += "↳ "
+		}
+
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s%s | %s |\n", marker, number, indent, name, status))
+		for _, child := range b.Children {
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 0)
+
+	sb.WriteString("\n<sub>Maintained by st</sub>\n")
+	sb.WriteString(StackNavEnd)
+	return sb.String()
+}
+
+// UpsertStackNav replaces the delimited stack section in body with section,
+// or appends it if body has none.
+func UpsertStackNav(body, section string) string {
+	start := strings.Index(body, StackNavStart)
+	end := strings.Index(body, StackNavEnd)
+	if start >= 0 && end > start {
+		return body[:start] + section + body[end+len(StackNavEnd):]
+	}
+
+	body = strings.TrimRight(body, "\n")
+	if body == "" {
+		return section
+	}
+	return body + "\n\n" + section
+}
@@ -0,0 +1,98 @@
+package forge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rodrigolobo/st/internal/stack"
+)
+
+func makeStack(branches map[string]string) *stack.Repo {
+	repo := &stack.Repo{Trunk: "main", Branches: make(map[string]*stack.Branch)}
+	for name, parent := range branches {
+		repo.Branches[name] = &stack.Branch{Name: name, Parent: parent}
+	}
+	stack.BuildTree(repo)
+	return repo
+}
+
+func TestRenderStackNav_Linear(t *testing.T) {
+	repo := makeStack(map[string]string{
+		"feat-a": "main",
+		"feat-b": "feat-a",
+		"feat-c": "feat-b",
+	})
+	prs := map[string]*PullRequest{
+		"feat-a": {Number: 1, State: StateMerged},
+		"feat-b": {Number: 2, State: StateOpen},
+		"feat-c": {Number: 3, State: StateOpen, Draft: true},
+	}
+
+	out := RenderStackNav(repo.Branches["feat-a"], "feat-b", prs)
+	if !strings.HasPrefix(out, StackNavStart) || !strings.HasSuffix(out, StackNavEnd) {
+		t.Error("section should be wrapped in markers")
+	}
+
+	lines := strings.Split(out, "\n")
+	var rows []string
+	for _, l := range lines {
+		if strings.HasPrefix(l, "| ") && !strings.HasPrefix(l, "| | PR") {
+			rows = append(rows, l)
+		}
+	}
+	expected := []string{
+		"| | | `main` | trunk |",
+		"|  | #1 | `feat-a` | merged |",
+		"| 👉 | #2 | &nbsp;&nbsp;↳ **`feat-b`** | open |",
+		"|  | #3 | &nbsp;&nbsp;&nbsp;&nbsp;↳ `feat-c` | draft |",
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d:\n%s", len(expected), len(rows), out)
+	}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("row %d:\n got: %s\nwant: %s", i, rows[i], expected[i])
+		}
+	}
+}
+
+func TestRenderStackNav_NotSubmitted(t *testing.T) {
+	repo := makeStack(map[string]string{
+		"feat-a": "main",
+		"feat-b": "feat-a",
+	})
+
+	out := RenderStackNav(repo.Branches["feat-a"], "feat-a", map[string]*PullRequest{
+		"feat-a": {Number: 7, State: StateOpen},
+	})
+	if !strings.Contains(out, "| — | &nbsp;&nbsp;↳ `feat-b` | not submitted |") {
+		t.Errorf("branch without PR should be marked not submitted:\n%s", out)
+	}
+}
+
+func TestUpsertStackNav_Append(t *testing.T) {
+	section := StackNavStart + "\nnew\n" + StackNavEnd
+
+	got := UpsertStackNav("Fixes the thing.\n", section)
+	if got != "Fixes the thing.\n\n"+section {
+		t.Errorf("unexpected body: %q", got)
+	}
+
+	if got := UpsertStackNav("", section); got != section {
+		t.Errorf("empty body should become the section, got %q", got)
+	}
+}
+
+func TestUpsertStackNav_Replace(t *testing.T) {
+	old := StackNavStart + "\nold\n" + StackNavEnd
+	section := StackNavStart + "\nnew\n" + StackNavEnd
+	body := "Intro\n\n" + old + "\n\nFooter"
+
+	got := UpsertStackNav(body, section)
+	if got != "Intro\n\n"+section+"\n\nFooter" {
+		t.Errorf("unexpected body: %q", got)
+	}
+	if again := UpsertStackNav(got, section); again != got {
+		t.Error("upserting the same section should be a no-op")
+	}
+}
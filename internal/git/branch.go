@@ -22,6 +22,17 @@ func CreateBranch(name string) error {
 	return RunSilent("checkout", "-b", name)
 }
 
+// IsValidBranchName reports whether git accepts name as a branch name.
+func IsValidBranchName(name string) bool {
+	return RunSilent("check-ref-format", "--branch", name) == nil
+}
+
+// CreateBranchAt creates a branch pointing at the given commit without
+// checking it out.
+func CreateBranchAt(name, sha string) error {
+	return RunSilent("branch", name, sha)
+}
+
 // Checkout switches to an existing branch.
 func Checkout(name string) error {
 	return RunSilent("checkout", name)
@@ -32,6 +43,11 @@ func DeleteBranch(name string) error {
 	return RunSilent("branch", "-D", name)
 }
 
+// RenameBranch renames a local branch. Its upstream setting moves with it.
+func RenameBranch(oldName, newName string) error {
+	return RunSilent("branch", "-m", oldName, newName)
+}
+
 // MergeBase returns the merge-base of two refs.
 func MergeBase(a, b string) (string, error) {
 	return Run("merge-base", a, b)
@@ -51,6 +67,16 @@ func CommitCount(ancestor, descendant string) (int, error) {
 	return strconv.Atoi(strings.TrimSpace(out))
 }
 
+// CommitsBetween returns the SHAs of the commits in ancestor..descendant,
+// oldest first.
+func CommitsBetween(ancestor, descendant string) ([]string, error) {
+	out, err := Run("rev-list", "--reverse", fmt.Sprintf("%s..%s", ancestor, descendant))
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
 // BranchTip returns the commit SHA at the tip of a branch.
 func BranchTip(branch string) (string, error) {
 	return RevParse(branch)
@@ -68,6 +94,21 @@ func RebaseContinue() error {
 	return err
 }
 
+// RebaseAbort runs git rebase --abort.
+func RebaseAbort() error {
+	return RunSilent("rebase", "--abort")
+}
+
+// ResetHard resets the current branch, index and working tree to a commit.
+func ResetHard(sha string) error {
+	return RunSilent("reset", "--hard", sha)
+}
+
+// SetBranchTip points a branch that is not checked out at a commit.
+func SetBranchTip(branch, sha string) error {
+	return RunSilent("update-ref", fmt.Sprintf("refs/heads/%s", branch), sha)
+}
+
 // IsRebaseInProgress checks if a git rebase is in progress.
 func IsRebaseInProgress() bool {
 	out, _ := Run("rev-parse", "--git-dir")
@@ -122,6 +163,28 @@ func ListLocalBranches() ([]string, error) {
 	return branches, nil
 }
 
+// BranchTips returns the tip SHA of every local branch.
+func BranchTips() (map[string]string, error) {
+	out, err := Run("for-each-ref", "--format=%(objectname) %(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	tips := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(parts) == 2 {
+			tips[parts[1]] = parts[0]
+		}
+	}
+	return tips, nil
+}
+
+// HasUncommittedChanges checks if there are staged or unstaged changes to tracked files.
+func HasUncommittedChanges() bool {
+	out, err := Run("status", "--porcelain", "--untracked-files=no")
+	return err != nil || out != ""
+}
+
 // IsBranchMergedInto checks if branch is merged into target.
 func IsBranchMergedInto(branch, target string) bool {
 	mb, err := MergeBase(branch, target)
@@ -135,7 +198,93 @@ func IsBranchMergedInto(branch, target string) bool {
 	return mb == tip
 }
 
+// FirstCommitMessage returns the subject and body of the oldest commit in parent..branch.
+func FirstCommitMessage(parent, branch string) (subject, body string, err error) {
+	out, err := Run("rev-list", "--reverse", fmt.Sprintf("%s..%s", parent, branch))
+	if err != nil {
+		return "", "", err
+	}
+	if out == "" {
+		return "", "", fmt.Errorf("no commits between %s and %s", parent, branch)
+	}
+	sha := strings.SplitN(out, "\n", 2)[0]
+	if subject, err = Run("log", "-1", "--format=%s", sha); err != nil {
+		return "", "", err
+	}
+	if body, err = Run("log", "-1", "--format=%b", sha); err != nil {
+		return "", "", err
+	}
+	return subject, body, nil
+}
+
+// IsAncestor checks if ancestor is reachable from descendant.
+func IsAncestor(ancestor, descendant string) bool {
+	return RunSilent("merge-base", "--is-ancestor", ancestor, descendant) == nil
+}
+
+// IsSquashMergedInto checks if the combined changes of base..branch landed on
+// target as a single commit, by comparing the patch-id of a throwaway squash
+// commit against the commits on target.
+func IsSquashMergedInto(branch, base, target string) bool {
+	tree, err := Run("rev-parse", branch+"^{tree}")
+	if err != nil {
+		return false
+	}
+	baseTree, err := Run("rev-parse", base+"^{tree}")
+	if err != nil || tree == baseTree {
+		return false
+	}
+	squashed, err := Run("commit-tree", tree, "-p", base, "-m", "st squash-merge check")
+	if err != nil {
+		return false
+	}
+	out, err := Run("cherry", target, squashed, base)
+	return err == nil && strings.HasPrefix(out, "-")
+}
+
+// IsRebaseMergedInto checks if every commit in base..branch has a
+// patch-equivalent commit on target.
+func IsRebaseMergedInto(branch, base, target string) bool {
+	out, err := Run("cherry", target, branch, base)
+	if err != nil || out == "" {
+		return false
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "-") {
+			return false
+		}
+	}
+	return true
+}
+
 // ShortLog returns a one-line log for a branch relative to its parent.
 func ShortLog(parent, branch string) (string, error) {
 	return Run("log", "--oneline", fmt.Sprintf("%s..%s", parent, branch))
 }
+
+// SquashCommit creates a single commit on top of base with branch's tree
+// and the messages of every commit in base..branch, oldest first. The new
+// commit is not referenced by any branch; its SHA is returned.
+func SquashCommit(base, branch string) (string, error) {
+	tree, err := Run("rev-parse", branch+"^{tree}")
+	if err != nil {
+		return "", err
+	}
+	message, err := CommitMessages(base, branch)
+	if err != nil {
+		return "", err
+	}
+	return CommitTree(tree, base, message)
+}
+
+// CommitMessages returns the full messages of every commit in base..branch,
+// oldest first, separated by blank lines.
+func CommitMessages(base, branch string) (string, error) {
+	return Run("log", "--reverse", "--format=%B", fmt.Sprintf("%s..%s", base, branch))
+}
+
+// CommitTree creates a commit with the given tree, parent and message
+// without touching any branch, and returns its SHA.
+func CommitTree(tree, parent, message string) (string, error) {
+	return Run("commit-tree", tree, "-p", parent, "-m", message)
+}
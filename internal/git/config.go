@@ -10,6 +10,12 @@ func ConfigGet(key string) (string, error) {
 	return Run("config", "--local", "--get", key)
 }
 
+// ConfigGetAny reads a config value from any scope (local, global or
+// system), for settings that are personal rather than per-repository.
+func ConfigGetAny(key string) (string, error) {
+	return Run("config", "--get", key)
+}
+
 // ConfigSet writes a git config value.
 func ConfigSet(key, value string) error {
 	return RunSilent("config", "--local", key, value)
@@ -28,7 +34,7 @@ func ConfigRemoveSection(section string) error {
 // ConfigGetRegexp returns all config entries matching a pattern.
 // Each result is a key-value pair.
 func ConfigGetRegexp(pattern string) ([][2]string, error) {
-	out, err := Run("config", "--local", "--get-regexp", pattern)
+	out, err := Run("config", "--local", "-z", "--get-regexp", pattern)
 	if err != nil {
 		// No matches is not an error for our purposes
 		if strings.Contains(err.Error(), "exit status 1") || out == "" {
@@ -36,36 +42,56 @@ func ConfigGetRegexp(pattern string) ([][2]string, error) {
 		}
 		return nil, err
 	}
-	if out == "" {
-		return nil, nil
-	}
+	return parseConfigEntries(out), nil
+}
 
+// parseConfigEntries parses the output of git config -z: NUL-terminated
+// entries, each a key and, unless it has no value, a newline and the value.
+// Keys can hold any character but a newline, so a subsection such as a
+// branch name with dots or quotes comes back intact.
+func parseConfigEntries(out string) [][2]string {
 	var results [][2]string
-	for _, line := range strings.Split(out, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
+	for _, entry := range strings.Split(out, "\x00") {
+		if entry == "" {
 			continue
 		}
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) == 2 {
-			results = append(results, [2]string{parts[0], parts[1]})
-		}
+		key, value, _ := strings.Cut(entry, "\n")
+		results = append(results, [2]string{key, value})
 	}
-	return results, nil
+	return results
 }
 
-// GetTrunk reads the configured trunk branch.
+// GetTrunk reads the primary trunk branch, the first one configured.
 func GetTrunk() (string, error) {
-	trunk, err := ConfigGet("st.trunk")
+	trunks, err := GetTrunks()
 	if err != nil {
-		return "", fmt.Errorf("st not initialized. Run 'st init' first")
+		return "", err
+	}
+	return trunks[0], nil
+}
+
+// GetTrunks reads every configured trunk branch, primary first.
+func GetTrunks() ([]string, error) {
+	out, err := Run("config", "--local", "--get-all", "st.trunk")
+	if err != nil || out == "" {
+		return nil, fmt.Errorf("st not initialized. Run 'st init' first")
 	}
-	return trunk, nil
+	return strings.Split(out, "\n"), nil
 }
 
-// SetTrunk writes the trunk branch to config.
+// SetTrunk makes branch the only trunk.
 func SetTrunk(branch string) error {
-	return ConfigSet("st.trunk", branch)
+	return RunSilent("config", "--local", "--replace-all", "st.trunk", branch)
+}
+
+// AddTrunk configures another trunk branch.
+func AddTrunk(branch string) error {
+	return RunSilent("config", "--local", "--add", "st.trunk", branch)
+}
+
+// RemoveTrunk stops treating branch as a trunk.
+func RemoveTrunk(branch string) error {
+	return RunSilent("config", "--local", "--fixed-value", "--unset-all", "st.trunk", branch)
 }
 
 // GetStackParent reads the parent of a stacked branch.
@@ -80,32 +106,136 @@ func SetStackParent(branch, parent string) error {
 	return ConfigSet(key, parent)
 }
 
+// GetStackBase reads the commit a stacked branch was last built on.
+func GetStackBase(branch string) (string, error) {
+	key := fmt.Sprintf("stack.%s.base", branch)
+	return ConfigGet(key)
+}
+
+// SetStackBase writes the commit a stacked branch was last built on.
+func SetStackBase(branch, sha string) error {
+	key := fmt.Sprintf("stack.%s.base", branch)
+	return ConfigSet(key, sha)
+}
+
+// UnsetStackBase removes the recorded base of a stacked branch.
+func UnsetStackBase(branch string) error {
+	key := fmt.Sprintf("stack.%s.base", branch)
+	return ConfigUnset(key)
+}
+
+// SetStackFrozen marks or unmarks a stacked branch as frozen.
+func SetStackFrozen(branch string, frozen bool) error {
+	key := fmt.Sprintf("stack.%s.frozen", branch)
+	if !frozen {
+		return ConfigUnset(key)
+	}
+	return ConfigSet(key, "true")
+}
+
 // RemoveStackSection removes the config section for a branch.
 func RemoveStackSection(branch string) error {
 	section := fmt.Sprintf("stack.%s", branch)
 	return ConfigRemoveSection(section)
 }
 
-// GetRestackState reads restack-in-progress state.
-func GetRestackState() (string, error) {
-	return ConfigGet("st.restack-remaining")
+// GetRestackState reads the branches a restack in progress has left to do.
+func GetRestackState() ([]string, error) {
+	if out, err := ConfigGet("st.restack-queue"); err == nil {
+		return splitRestackQueue(out), nil
+	}
+	// Restacks started by older versions joined the queue with commas
+	out, err := ConfigGet("st.restack-remaining")
+	if err != nil {
+		return nil, err
+	}
+	return splitLegacyRestackQueue(out), nil
 }
 
-// SetRestackState saves restack-in-progress state.
-func SetRestackState(remaining string) error {
+// SetRestackState saves the branches a restack in progress has left to do.
+// They are kept one per line, since branch names can't contain newlines but
+// can contain commas.
+func SetRestackState(remaining []string) error {
 	if err := ConfigSet("st.restack-in-progress", "true"); err != nil {
 		return err
 	}
-	return ConfigSet("st.restack-remaining", remaining)
+	_ = ConfigUnset("st.restack-remaining")
+	return ConfigSet("st.restack-queue", strings.Join(remaining, "\n"))
+}
+
+func splitRestackQueue(queue string) []string {
+	var names []string
+	for _, name := range strings.Split(queue, "\n") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func splitLegacyRestackQueue(queue string) []string {
+	var names []string
+	for _, name := range strings.Split(queue, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 // ClearRestackState removes restack-in-progress state.
 func ClearRestackState() error {
 	_ = ConfigUnset("st.restack-in-progress")
+	_ = ConfigUnset("st.restack-queue")
 	_ = ConfigUnset("st.restack-remaining")
+	_ = ConfigUnset("st.restack-snapshot")
+	_ = ConfigUnset("st.restack-head")
 	return nil
 }
 
+// SetRestackSnapshot records the branch tips and bases, and the checked-out
+// branch, from before a restack started, so the restack can be rolled back.
+func SetRestackSnapshot(head string, tips, bases map[string]string) error {
+	var lines []string
+	for branch, sha := range tips {
+		base := bases[branch]
+		if base == "" {
+			base = "-"
+		}
+		lines = append(lines, sha+" "+base+" "+branch)
+	}
+	if err := ConfigSet("st.restack-head", head); err != nil {
+		return err
+	}
+	return ConfigSet("st.restack-snapshot", strings.Join(lines, "\n"))
+}
+
+// GetRestackSnapshot reads the branch tips and bases recorded before a restack started.
+func GetRestackSnapshot() (tips, bases map[string]string, err error) {
+	out, err := ConfigGet("st.restack-snapshot")
+	if err != nil {
+		return nil, nil, err
+	}
+	tips = make(map[string]string)
+	bases = make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		tips[parts[2]] = parts[0]
+		if parts[1] != "-" {
+			bases[parts[2]] = parts[1]
+		}
+	}
+	return tips, bases, nil
+}
+
+// GetRestackHead reads the branch that was checked out when the restack started.
+func GetRestackHead() (string, error) {
+	return ConfigGet("st.restack-head")
+}
+
 // IsRestackInProgress checks if a restack is in progress.
 func IsRestackInProgress() bool {
 	val, err := ConfigGet("st.restack-in-progress")
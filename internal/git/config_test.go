@@ -0,0 +1,59 @@
+package git
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseConfigEntries(t *testing.T) {
+	out := "stack.release.2.x-fix.parent\nmain\x00" +
+		"stack.a b.parent\nfeat with space\x00" +
+		"stack.Feat/Ünïcode.base\n0123abc\x00" +
+		"st.restack-queue\na\nb,c\x00" +
+		"core.bare\x00"
+	want := [][2]string{
+		{"stack.release.2.x-fix.parent", "main"},
+		{"stack.a b.parent", "feat with space"},
+		{"stack.Feat/Ünïcode.base", "0123abc"},
+		{"st.restack-queue", "a\nb,c"},
+		{"core.bare", ""},
+	}
+	if got := parseConfigEntries(out); !slices.Equal(got, want) {
+		t.Errorf("parseConfigEntries = %q, want %q", got, want)
+	}
+	if got := parseConfigEntries(""); got != nil {
+		t.Errorf("parseConfigEntries(\"\") = %q, want nil", got)
+	}
+}
+
+func TestSplitRestackQueue(t *testing.T) {
+	tests := []struct {
+		queue string
+		want  []string
+	}{
+		{"feat", []string{"feat"}},
+		{"a\nrelease.2.x\nfix,comma", []string{"a", "release.2.x", "fix,comma"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := splitRestackQueue(tt.queue); !slices.Equal(got, tt.want) {
+			t.Errorf("splitRestackQueue(%q) = %q, want %q", tt.queue, got, tt.want)
+		}
+	}
+}
+
+func TestSplitLegacyRestackQueue(t *testing.T) {
+	tests := []struct {
+		queue string
+		want  []string
+	}{
+		{"a,b", []string{"a", "b"}},
+		{"a, b,", []string{"a", "b"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := splitLegacyRestackQueue(tt.queue); !slices.Equal(got, tt.want) {
+			t.Errorf("splitLegacyRestackQueue(%q) = %q, want %q", tt.queue, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// FileDiff is one file's section of a unified diff.
+type FileDiff struct {
+	Path   string
+	Header []string // "diff --git" line through "+++", or binary patch data
+	Hunks  []Hunk
+}
+
+// Hunk is a single "@@" hunk of a FileDiff.
+type Hunk struct {
+	Header string   // the "@@ ... @@" line
+	Lines  []string // context, added and removed lines
+}
+
+// Units returns how many independently assignable pieces a file has: one
+// per hunk, or one for the whole file if it has no hunks (binary files,
+// mode changes, pure renames).
+func (f FileDiff) Units() int {
+	if len(f.Hunks) == 0 {
+		return 1
+	}
+	return len(f.Hunks)
+}
+
+// Diff returns the full binary-safe diff between two commits.
+func Diff(from, to string) (string, error) {
+	return runRaw("diff", "--no-color", "--no-ext-diff", "--binary", "--full-index", from, to)
+}
+
+// ParseDiff splits a unified diff into files and hunks.
+func ParseDiff(patch string) []FileDiff {
+	var files []FileDiff
+	var file *FileDiff
+	var hunk *Hunk
+
+	for _, line := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, FileDiff{Path: diffPath(line), Header: []string{line}})
+			file = &files[len(files)-1]
+			hunk = nil
+		case file == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			file.Hunks = append(file.Hunks, Hunk{Header: line})
+			hunk = &file.Hunks[len(file.Hunks)-1]
+		case hunk != nil:
+			hunk.Lines = append(hunk.Lines, line)
+		default:
+			file.Header = append(file.Header, line)
+		}
+	}
+	return files
+}
+
+// BuildPatch reassembles a diff from the units for which keep returns true.
+// Files with no kept units are left out.
+func BuildPatch(files []FileDiff, keep func(file, unit int) bool) string {
+	var sb strings.Builder
+	for i, f := range files {
+		if len(f.Hunks) == 0 {
+			if keep(i, 0) {
+				writeLines(&sb, f.Header)
+			}
+			continue
+		}
+
+		var hunks []Hunk
+		for j, h := range f.Hunks {
+			if keep(i, j) {
+				hunks = append(hunks, h)
+			}
+		}
+		if len(hunks) == 0 {
+			continue
+		}
+		writeLines(&sb, f.Header)
+		for _, h := range hunks {
+			sb.WriteString(h.Header + "\n")
+			writeLines(&sb, h.Lines)
+		}
+	}
+	return sb.String()
+}
+
+// ApplyToTree applies patch to the tree of base in a scratch index, leaving
+// the real index and working tree alone, and returns the resulting tree.
+func ApplyToTree(base, patch string) (string, error) {
+	if patch == "" {
+		return Run("rev-parse", base+"^{tree}")
+	}
+
+	index, err := os.CreateTemp("", "st-index-")
+	if err != nil {
+		return "", err
+	}
+	index.Close()
+	defer os.Remove(index.Name())
+
+	patchFile, err := os.CreateTemp("", "st-patch-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(patchFile.Name())
+	if _, err := patchFile.WriteString(patch); err != nil {
+		patchFile.Close()
+		return "", err
+	}
+	patchFile.Close()
+
+	env := append(os.Environ(), "GIT_INDEX_FILE="+index.Name())
+	steps := [][]string{
+		{"read-tree", base},
+		{"apply", "--cached", "--recount", patchFile.Name()},
+		{"write-tree"},
+	}
+	var out string
+	for _, args := range steps {
+		cmd := exec.Command("git", args...)
+		cmd.Env = env
+		raw, err := cmd.CombinedOutput()
+		out = strings.TrimSpace(string(raw))
+		if err != nil {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), out)
+		}
+	}
+	return out, nil
+}
+
+// diffPath extracts the destination path from a "diff --git a/x b/y" line.
+func diffPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return rest
+}
+
+func writeLines(sb *strings.Builder, lines []string) {
+	for _, l := range lines {
+		sb.WriteString(l + "\n")
+	}
+}
+
+// runRaw executes a git command and returns its stdout untrimmed.
+func runRaw(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,82 @@
+package git
+
+import (
+	"testing"
+)
+
+const samplePatch = `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+-one
++ONE
+ two
+ three
+@@ -10,2 +10,3 @@ func f() {
+ ten
++ten and a half
+ eleven
+diff --git a/img.png b/img.png
+new file mode 100644
+index 0000000..3333333
+GIT binary patch
+literal 4
+LcmZ?wbhKY
+
+diff --git a/b.txt b/b.txt
+index 4444444..5555555 100644
+--- a/b.txt
++++ b/b.txt
+@@ -1 +1 @@
+-x
++y
+`
+
+func TestParseDiff(t *testing.T) {
+	files := ParseDiff(samplePatch)
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(files))
+	}
+
+	if files[0].Path != "a.txt" || len(files[0].Hunks) != 2 || files[0].Units() != 2 {
+		t.Errorf("unexpected first file: %+v", files[0])
+	}
+	if files[0].Hunks[1].Header != "@@ -10,2 +10,3 @@ func f() {" || len(files[0].Hunks[1].Lines) != 3 {
+		t.Errorf("unexpected second hunk: %+v", files[0].Hunks[1])
+	}
+	if files[1].Path != "img.png" || len(files[1].Hunks) != 0 || files[1].Units() != 1 {
+		t.Errorf("binary file should be a single unit: %+v", files[1])
+	}
+	if files[2].Path != "b.txt" || len(files[2].Header) != 4 {
+		t.Errorf("unexpected third file: %+v", files[2])
+	}
+}
+
+func TestBuildPatch_RoundTrip(t *testing.T) {
+	files := ParseDiff(samplePatch)
+	got := BuildPatch(files, func(int, int) bool { return true })
+	if got != samplePatch {
+		t.Errorf("round trip changed the patch:\n%s", got)
+	}
+}
+
+func TestBuildPatch_Subset(t *testing.T) {
+	files := ParseDiff(samplePatch)
+	got := BuildPatch(files, func(file, unit int) bool {
+		return file == 0 && unit == 1
+	})
+
+	want := `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -10,2 +10,3 @@ func f() {
+ ten
++ten and a half
+ eleven
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
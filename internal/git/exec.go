@@ -42,3 +42,8 @@ func IsInsideWorkTree() bool {
 func TopLevel() (string, error) {
 	return Run("rev-parse", "--show-toplevel")
 }
+
+// CommonDir returns the absolute path of the git directory shared by all worktrees.
+func CommonDir() (string, error) {
+	return Run("rev-parse", "--path-format=absolute", "--git-common-dir")
+}
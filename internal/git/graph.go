@@ -0,0 +1,147 @@
+package git
+
+import (
+	"strings"
+)
+
+// Graph is the part of the commit graph above the merge-base of a set of
+// commits, loaded with a single rev-list. Every loaded commit descends from
+// that merge-base, and every commit that was not loaded is an ancestor of
+// it. Queries report ok=false when the answer depends on commits outside
+// the graph; callers should then ask git.
+type Graph struct {
+	parents map[string][]string // loaded commit -> its parents
+	base    string              // the merge-base; "" if the graph is unusable
+}
+
+// LoadGraph loads the commits reachable from any of shas but not from their
+// common merge-base.
+func LoadGraph(shas []string) (*Graph, error) {
+	if len(shas) == 0 {
+		return &Graph{}, nil
+	}
+	base, err := Run(append([]string{"merge-base", "--octopus"}, shas...)...)
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"rev-list", "--parents", "--boundary"}, shas...)
+	args = append(args, "^"+base, "--")
+	out, err := Run(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseGraph(out, base), nil
+}
+
+// parseGraph parses the output of rev-list --parents --boundary run
+// against ^base. If any loaded commit has a parent other than base below
+// the loaded commits (history merged in from beside base), the graph can't
+// answer queries exactly and is left unusable.
+func parseGraph(out, base string) *Graph {
+	g := &Graph{parents: make(map[string][]string), base: base}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if sha, ok := strings.CutPrefix(fields[0], "-"); ok {
+			if sha != base {
+				g.base = ""
+			}
+			continue
+		}
+		g.parents[fields[0]] = fields[1:]
+	}
+	return g
+}
+
+// known reports whether the graph can place sha: it was loaded, or it is
+// the merge-base below them.
+func (g *Graph) known(sha string) bool {
+	if g.base == "" {
+		return false
+	}
+	_, loaded := g.parents[sha]
+	return loaded || sha == g.base
+}
+
+// ancestors returns the loaded commits reachable from any of shas,
+// including themselves.
+func (g *Graph) ancestors(shas ...string) map[string]bool {
+	seen := make(map[string]bool)
+	stack := append([]string{}, shas...)
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		parents, loaded := g.parents[c]
+		if !loaded || seen[c] {
+			continue
+		}
+		seen[c] = true
+		stack = append(stack, parents...)
+	}
+	return seen
+}
+
+// IsAncestor reports whether ancestor is reachable from descendant.
+func (g *Graph) IsAncestor(ancestor, descendant string) (result, ok bool) {
+	if !g.known(ancestor) || !g.known(descendant) {
+		return false, false
+	}
+	if ancestor == g.base {
+		return true, true
+	}
+	return g.ancestors(descendant)[ancestor], true
+}
+
+// MergeBase returns the best common ancestor of a and b.
+func (g *Graph) MergeBase(a, b string) (string, bool) {
+	if !g.known(a) || !g.known(b) {
+		return "", false
+	}
+	ancA, ancB := g.ancestors(a), g.ancestors(b)
+	var common []string
+	for c := range ancA {
+		if ancB[c] {
+			common = append(common, c)
+		}
+	}
+
+	if len(common) == 0 {
+		return g.base, true
+	}
+
+	// The best common ancestors are those no other common ancestor
+	// descends from
+	var parents []string
+	for _, c := range common {
+		parents = append(parents, g.parents[c]...)
+	}
+	below := g.ancestors(parents...)
+	var best []string
+	for _, c := range common {
+		if !below[c] {
+			best = append(best, c)
+		}
+	}
+	if len(best) != 1 {
+		return "", false
+	}
+	return best[0], true
+}
+
+// Count returns the number of commits reachable from descendant but not
+// from ancestor, like rev-list --count ancestor..descendant.
+func (g *Graph) Count(ancestor, descendant string) (int, bool) {
+	if !g.known(ancestor) || !g.known(descendant) {
+		return 0, false
+	}
+	exclude := g.ancestors(ancestor)
+	n := 0
+	for c := range g.ancestors(descendant) {
+		if !exclude[c] {
+			n++
+		}
+	}
+	return n, true
+}
@@ -0,0 +1,92 @@
+package git
+
+import "testing"
+
+// testGraph is:
+//
+//	      d - e      (e merges c)
+//	     /   /
+//	o - a - b - c
+//	     \
+//	      f
+func testGraph() *Graph {
+	return parseGraph(`a o
+b a
+c b
+d a
+e d c
+f a
+-o`, "o")
+}
+
+func TestGraph_IsAncestor(t *testing.T) {
+	g := testGraph()
+	tests := []struct {
+		a, d string
+		want bool
+	}{
+		{"o", "c", true},
+		{"o", "o", true},
+		{"a", "c", true},
+		{"c", "e", true},
+		{"c", "a", false},
+		{"f", "e", false},
+		{"e", "e", true},
+		{"b", "o", false},
+	}
+	for _, tt := range tests {
+		got, ok := g.IsAncestor(tt.a, tt.d)
+		if !ok || got != tt.want {
+			t.Errorf("IsAncestor(%s, %s) = %v, %v; want %v, true", tt.a, tt.d, got, ok, tt.want)
+		}
+	}
+	if _, ok := g.IsAncestor("zzz", "c"); ok {
+		t.Error("IsAncestor should not answer for unknown commits")
+	}
+}
+
+func TestGraph_MergeBase(t *testing.T) {
+	g := testGraph()
+	tests := []struct{ a, b, want string }{
+		{"c", "f", "a"},
+		{"c", "d", "a"},
+		{"e", "c", "c"},
+		{"e", "f", "a"},
+		{"o", "c", "o"},
+		{"b", "b", "b"},
+	}
+	for _, tt := range tests {
+		got, ok := g.MergeBase(tt.a, tt.b)
+		if !ok || got != tt.want {
+			t.Errorf("MergeBase(%s, %s) = %q, %v; want %q, true", tt.a, tt.b, got, ok, tt.want)
+		}
+	}
+}
+
+func TestGraph_Count(t *testing.T) {
+	g := testGraph()
+	tests := []struct {
+		a, d string
+		want int
+	}{
+		{"o", "c", 3},
+		{"a", "c", 2},
+		{"c", "e", 2},
+		{"f", "e", 4},
+		{"c", "a", 0},
+	}
+	for _, tt := range tests {
+		got, ok := g.Count(tt.a, tt.d)
+		if !ok || got != tt.want {
+			t.Errorf("Count(%s, %s) = %d, %v; want %d, true", tt.a, tt.d, got, ok, tt.want)
+		}
+	}
+}
+
+func TestGraph_SideHistoryIsUnusable(t *testing.T) {
+	// c merges in x, which forked off below o
+	g := parseGraph("a o\nc a x\n-o\n-x", "o")
+	if _, ok := g.Count("o", "c"); ok {
+		t.Error("a graph with history from beside its base should not answer")
+	}
+}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// HashObject writes content to the object database as a blob and returns
+// its SHA.
+func HashObject(content string) (string, error) {
+	cmd := exec.Command("git", "hash-object", "-w", "--stdin")
+	cmd.Stdin = strings.NewReader(content)
+	out, err := cmd.CombinedOutput()
+	result := strings.TrimSpace(string(out))
+	if err != nil {
+		return "", fmt.Errorf("git hash-object: %s", result)
+	}
+	return result, nil
+}
+
+// ReadBlob returns the content of the blob a ref or SHA points to.
+func ReadBlob(ref string) (string, error) {
+	return runRaw("cat-file", "blob", ref)
+}
+
+// ListRefs returns the object each ref under prefix points to, keyed by the
+// ref name with prefix removed.
+func ListRefs(prefix string) (map[string]string, error) {
+	out, err := Run("for-each-ref", "--format=%(objectname) %(refname)", prefix)
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[string]string)
+	if out == "" {
+		return refs, nil
+	}
+	for _, line := range strings.Split(out, "\n") {
+		sha, ref, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		refs[strings.TrimPrefix(ref, prefix)] = sha
+	}
+	return refs, nil
+}
+
+// UpdateRef points ref at sha, creating it if needed.
+func UpdateRef(ref, sha string) error {
+	return RunSilent("update-ref", ref, sha)
+}
+
+// DeleteRef removes a ref.
+func DeleteRef(ref string) error {
+	return RunSilent("update-ref", "-d", ref)
+}
+
+// PushRefs force-pushes every ref matching a refspec source pattern to the
+// same name on a remote.
+func PushRefs(remote, pattern string) error {
+	return RunSilent("push", remote, fmt.Sprintf("+%s:%s", pattern, pattern))
+}
+
+// FetchRefs fetches refs matching src on a remote into dst, pruning refs
+// under dst that no longer exist on the remote.
+func FetchRefs(remote, src, dst string) error {
+	return RunSilent("fetch", "--prune", remote, fmt.Sprintf("+%s:%s", src, dst))
+}
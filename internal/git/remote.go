@@ -2,6 +2,7 @@ package git
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -20,6 +21,16 @@ func PushForce(remote, branch string) error {
 	return RunSilent("push", "--force-with-lease", remote, branch)
 }
 
+// SetUpstream sets the upstream of a local branch to the same-named branch on a remote.
+func SetUpstream(branch, remote string) error {
+	return RunSilent("branch", fmt.Sprintf("--set-upstream-to=%s/%s", remote, branch), branch)
+}
+
+// RemoteURL returns the fetch URL of a remote.
+func RemoteURL(remote string) (string, error) {
+	return Run("remote", "get-url", remote)
+}
+
 // FastForward fast-forwards a local branch to its remote tracking branch.
 func FastForward(branch, remote string) error {
 	remoteBranch := fmt.Sprintf("%s/%s", remote, branch)
@@ -52,3 +63,48 @@ func HasRemote() bool {
 	out, err := Run("remote")
 	return err == nil && strings.TrimSpace(out) != ""
 }
+
+// Upstream is the upstream of a local branch and how the two have diverged,
+// as of the last fetch.
+type Upstream struct {
+	Ref    string // e.g. "origin/feat-auth"
+	Ahead  int    // local commits not on the upstream
+	Behind int    // upstream commits not on the local branch
+	Gone   bool   // the upstream branch was deleted on the remote
+}
+
+// Upstreams returns the upstream of every local branch that has one, keyed
+// by branch name, in a single git call.
+func Upstreams() (map[string]Upstream, error) {
+	out, err := Run("for-each-ref", "--format=%(refname:short)\t%(upstream:short)\t%(upstream:track)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	return parseUpstreams(out), nil
+}
+
+// parseUpstreams parses the output of the for-each-ref in Upstreams.
+func parseUpstreams(out string) map[string]Upstream {
+	upstreams := make(map[string]Upstream)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[1] == "" {
+			continue
+		}
+		u := Upstream{Ref: fields[1]}
+		track := strings.Trim(fields[2], "[]")
+		for _, part := range strings.Split(track, ", ") {
+			kind, n, _ := strings.Cut(part, " ")
+			switch kind {
+			case "ahead":
+				u.Ahead, _ = strconv.Atoi(n)
+			case "behind":
+				u.Behind, _ = strconv.Atoi(n)
+			case "gone":
+				u.Gone = true
+			}
+		}
+		upstreams[fields[0]] = u
+	}
+	return upstreams
+}
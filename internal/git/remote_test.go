@@ -0,0 +1,32 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseUpstreams(t *testing.T) {
+	out := "main\torigin/main\t\n" +
+		"ahead\torigin/ahead\t[ahead 2]\n" +
+		"behind\torigin/behind\t[behind 3]\n" +
+		"diverged\torigin/diverged\t[ahead 1, behind 4]\n" +
+		"deleted\torigin/deleted\t[gone]\n" +
+		"local\t\t"
+
+	want := map[string]Upstream{
+		"main":     {Ref: "origin/main"},
+		"ahead":    {Ref: "origin/ahead", Ahead: 2},
+		"behind":   {Ref: "origin/behind", Behind: 3},
+		"diverged": {Ref: "origin/diverged", Ahead: 1, Behind: 4},
+		"deleted":  {Ref: "origin/deleted", Gone: true},
+	}
+	if got := parseUpstreams(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseUpstreams_Empty(t *testing.T) {
+	if got := parseUpstreams(""); len(got) != 0 {
+		t.Errorf("expected no upstreams, got %+v", got)
+	}
+}
@@ -0,0 +1,157 @@
+package stack
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultBranchTemplate is used when st.branch-template is not set.
+const DefaultBranchTemplate = "{slug}"
+
+// maxSlugLen caps the part of a generated name taken from the message.
+const maxSlugLen = 40
+
+var (
+	issuePattern       = regexp.MustCompile(`\b([A-Z]{2}[A-Z0-9]*)-[0-9]+\b`)
+	placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+)
+
+// notIssuePrefixes look like issue key prefixes but name encodings, hashes,
+// protocols and standards, as in UTF-8, SHA-256 or HTTP-2.
+var notIssuePrefixes = map[string]bool{
+	"AES": true, "CVE": true, "HTTP": true, "ISO": true, "MD": true,
+	"RFC": true, "RSA": true, "SHA": true, "SSL": true, "TLS": true,
+	"UCS": true, "UTF": true,
+}
+
+// BranchNameInput is what a branch template can refer to.
+type BranchNameInput struct {
+	Message string    // commit message; gives {slug} and {issue}
+	User    string    // user.email or user.name; gives {user}
+	Date    time.Time // gives {date} as YYYY-MM-DD
+}
+
+// GenerateBranchName expands template into a branch name. Placeholders are
+// {user} (the part of the email before the @, slugified), {date}, {issue}
+// (the first Jira-style key such as PROJ-123 in the message, or nothing; see
+// findIssue)
+// and {slug} (the rest of the message's first line, lowercased, with runs of
+// anything but letters and digits turned into dashes). Separators left
+// dangling by empty placeholders are dropped. The issue key is left out of
+// {slug} only when the template has {issue}. If the name is taken, -2, -3
+// and so on is appended. valid reports whether git accepts a name.
+func GenerateBranchName(template string, in BranchNameInput, exists, valid func(string) bool) (string, error) {
+	subject, _, _ := strings.Cut(strings.TrimSpace(in.Message), "\n")
+	issue := findIssue(subject)
+	if strings.Contains(template, "{issue}") {
+		// The key has a placeholder of its own; keep it out of the slug
+		subject = strings.Replace(subject, issue, "", 1)
+	}
+	slug := truncateSlug(Slugify(subject), maxSlugLen)
+	if slug == "" && strings.Contains(template, "{slug}") {
+		return "", fmt.Errorf("could not derive a branch name from %q. Pass a name", subject)
+	}
+
+	user, _, _ := strings.Cut(in.User, "@")
+	vars := map[string]string{
+		"{user}":  Slugify(user),
+		"{date}":  in.Date.Format("2006-01-02"),
+		"{issue}": issue,
+		"{slug}":  slug,
+	}
+	var unknown string
+	name := placeholderPattern.ReplaceAllStringFunc(template, func(p string) string {
+		v, ok := vars[p]
+		if !ok && unknown == "" {
+			unknown = p
+		}
+		return v
+	})
+	if unknown != "" {
+		return "", fmt.Errorf("unknown placeholder %s in branch template %q", unknown, template)
+	}
+
+	name = tidyBranchName(name)
+	if name == "" || !valid(name) {
+		return "", fmt.Errorf("branch template %q gives %q, which is not a valid branch name", template, name)
+	}
+	if !exists(name) {
+		return name, nil
+	}
+	for n := 2; ; n++ {
+		if candidate := fmt.Sprintf("%s-%d", name, n); !exists(candidate) {
+			return candidate, nil
+		}
+	}
+}
+
+// findIssue returns the first issue key in s: two or more capital letters,
+// optionally followed by more letters and digits, then a dash and a number.
+// Keys starting with a prefix in notIssuePrefixes are skipped.
+func findIssue(s string) string {
+	for _, m := range issuePattern.FindAllStringSubmatch(s, -1) {
+		if !notIssuePrefixes[m[1]] {
+			return m[0]
+		}
+	}
+	return ""
+}
+
+// Slugify lowercases s and turns every run of characters other than letters
+// and digits into a single dash, trimming dashes from both ends.
+func Slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// truncateSlug shortens a slug to at most max bytes, cutting at a dash
+// when there is one to cut at.
+func truncateSlug(slug string, max int) string {
+	if len(slug) <= max {
+		return slug
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(slug[cut]) {
+		cut--
+	}
+	if slug[cut] != '-' {
+		if i := strings.LastIndexByte(slug[:cut], '-'); i > 0 {
+			cut = i
+		}
+	}
+	return strings.TrimRight(slug[:cut], "-")
+}
+
+// tidyBranchName drops the separators an empty placeholder leaves behind:
+// empty path components, and dashes, underscores and dots at either end of
+// a component or repeated within one.
+func tidyBranchName(name string) string {
+	var parts []string
+	for _, part := range strings.Split(name, "/") {
+		for _, sep := range []string{"--", "__", ".."} {
+			for strings.Contains(part, sep) {
+				part = strings.ReplaceAll(part, sep, sep[:1])
+			}
+		}
+		if part = strings.Trim(part, "-_."); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,113 @@
+package stack
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Add auth layer", "add-auth-layer"},
+		{"  fix: handle nil   pointer!! ", "fix-handle-nil-pointer"},
+		{"Use v2.0 API (finally)", "use-v2-0-api-finally"},
+		{"feat/auth_ui", "feat-auth-ui"},
+		{"Añadir autenticación", "añadir-autenticación"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.in); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateSlug(t *testing.T) {
+	tests := []struct {
+		slug string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"add-auth-layer-to-api", 12, "add-auth"},
+		{"add-auth-layer", 8, "add-auth"},
+		{"averyveryverylongword", 5, "avery"},
+		{"añadir", 2, "a"},
+	}
+	for _, tt := range tests {
+		if got := truncateSlug(tt.slug, tt.max); got != tt.want {
+			t.Errorf("truncateSlug(%q, %d) = %q, want %q", tt.slug, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateBranchName(t *testing.T) {
+	in := BranchNameInput{
+		Message: "PROJ-42 Add auth layer\n\nLonger description.",
+		User:    "Jane.Doe@example.com",
+		Date:    time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		template string
+		message  string // overrides in.Message if set
+		want     string
+	}{
+		{"{slug}", "", "proj-42-add-auth-layer"},
+		{"{user}/{date}-{slug}", "", "jane-doe/2026-03-07-proj-42-add-auth-layer"},
+		{"{issue}-{slug}", "", "PROJ-42-add-auth-layer"},
+		{"{issue}-{slug}", "Add auth layer", "add-auth-layer"},
+		{"{user}/{issue}/{slug}", "Add auth layer", "jane-doe/add-auth-layer"},
+		{"feature/{slug}", "Fix the [ABC-1] bug", "feature/fix-the-abc-1-bug"},
+		{"{issue}-{slug}", "Fix the [ABC-1] bug", "ABC-1-fix-the-bug"},
+		{"{slug}", "Add UTF-8 support", "add-utf-8-support"},
+		{"{slug}", "Fix SHA-256 hashing", "fix-sha-256-hashing"},
+		{"{issue}-{slug}", "Switch to SHA-256", "switch-to-sha-256"},
+		{"{issue}-{slug}", "Add UTF-8 support", "add-utf-8-support"},
+		{"{issue}-{slug}", "Serve over HTTP-2", "serve-over-http-2"},
+		{"{issue}-{slug}", "Handle the X-1 header", "handle-the-x-1-header"},
+		{"{issue}-{slug}", "Use SHA-256 for PROJ-7", "PROJ-7-use-sha-256-for"},
+		{"{issue}-{slug}", "Fix AB2C-9 crash", "AB2C-9-fix-crash"},
+		{"{slug}", "Rework the session handling so tokens refresh in the background", "rework-the-session-handling-so-tokens"},
+	}
+	for _, tt := range tests {
+		in := in
+		if tt.message != "" {
+			in.Message = tt.message
+		}
+		got, err := GenerateBranchName(tt.template, in, func(string) bool { return false }, func(string) bool { return true })
+		if err != nil || got != tt.want {
+			t.Errorf("GenerateBranchName(%q, %q) = %q, %v, want %q", tt.template, in.Message, got, err, tt.want)
+		}
+	}
+}
+
+func TestGenerateBranchName_Unique(t *testing.T) {
+	taken := map[string]bool{"add-auth": true, "add-auth-2": true}
+	got, err := GenerateBranchName("{slug}", BranchNameInput{Message: "Add auth"},
+		func(name string) bool { return taken[name] }, func(string) bool { return true })
+	if err != nil || got != "add-auth-3" {
+		t.Errorf("expected add-auth-3, got %q (%v)", got, err)
+	}
+}
+
+func TestGenerateBranchName_Errors(t *testing.T) {
+	tests := []struct {
+		template, message string
+		valid             bool
+		want              string // substring of the error
+	}{
+		{"{slug}", "!!!", true, "could not derive"},
+		{"{team}/{slug}", "Add auth", true, "unknown placeholder {team}"},
+		{"{slug}", "Add auth", false, "not a valid branch name"},
+		{"{issue}", "Add auth", true, "not a valid branch name"},
+	}
+	for _, tt := range tests {
+		_, err := GenerateBranchName(tt.template, BranchNameInput{Message: tt.message},
+			func(string) bool { return false }, func(string) bool { return tt.valid })
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("GenerateBranchName(%q, %q): expected error containing %q, got %v", tt.template, tt.message, tt.want, err)
+		}
+	}
+}
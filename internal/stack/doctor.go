@@ -0,0 +1,242 @@
+package stack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// ProblemKind classifies a problem found by Diagnose.
+type ProblemKind string
+
+const (
+	ProblemNoTrunk       ProblemKind = "no-trunk"       // st.trunk is not set
+	ProblemMissingTrunk  ProblemKind = "missing-trunk"  // st.trunk names a branch that doesn't exist
+	ProblemTrackedTrunk  ProblemKind = "tracked-trunk"  // trunk has stack metadata of its own
+	ProblemMissingBranch ProblemKind = "missing-branch" // tracked branch no longer exists locally
+	ProblemMissingParent ProblemKind = "missing-parent" // parent is neither a local branch nor tracked
+	ProblemCycle         ProblemKind = "cycle"          // parents loop back on themselves
+	ProblemStaleRestack  ProblemKind = "stale-restack"  // st.restack-* state left behind
+)
+
+// Problem is one inconsistency in the stack metadata.
+type Problem struct {
+	Kind   ProblemKind
+	Branch string // the affected branch, if any
+	Detail string
+}
+
+// Fix describes what FixProblem will do about p.
+func (p Problem) Fix() string {
+	switch p.Kind {
+	case ProblemNoTrunk:
+		return "set trunk to main or master, whichever exists"
+	case ProblemMissingTrunk:
+		return fmt.Sprintf("stop treating %s as a trunk, falling back to main or master if no trunk is left", p.Branch)
+	case ProblemTrackedTrunk:
+		return fmt.Sprintf("remove the stack metadata of %s", p.Branch)
+	case ProblemMissingBranch:
+		return fmt.Sprintf("untrack %s and move its children onto its parent", p.Branch)
+	case ProblemMissingParent, ProblemCycle:
+		return fmt.Sprintf("move %s onto trunk", p.Branch)
+	case ProblemStaleRestack:
+		return "clear the restack state"
+	}
+	return ""
+}
+
+// Diagnose checks the trunk setting, stack metadata and restack state for
+// problems.
+func Diagnose() ([]Problem, error) {
+	parents, _, _, err := loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	local, err := git.ListLocalBranches()
+	if err != nil {
+		return nil, fmt.Errorf("could not list branches: %w", err)
+	}
+	exists := make(map[string]bool, len(local))
+	for _, name := range local {
+		exists[name] = true
+	}
+
+	trunks, _ := git.GetTrunks()
+	problems := diagnose(trunks, parents, exists)
+
+	if p, ok := diagnoseRestack(parents, exists); ok {
+		problems = append(problems, p)
+	}
+	return problems, nil
+}
+
+// diagnose finds trunk and metadata problems, given the configured trunks,
+// every tracked branch's parent and the set of local branches.
+func diagnose(trunks []string, parents map[string]string, exists map[string]bool) []Problem {
+	var problems []Problem
+
+	if len(trunks) == 0 {
+		problems = append(problems, Problem{Kind: ProblemNoTrunk, Detail: "no trunk branch is configured"})
+	}
+	isTrunk := make(map[string]bool, len(trunks))
+	for _, trunk := range trunks {
+		isTrunk[trunk] = true
+		if !exists[trunk] {
+			problems = append(problems, Problem{Kind: ProblemMissingTrunk, Branch: trunk,
+				Detail: fmt.Sprintf("trunk branch %s does not exist", trunk)})
+		}
+	}
+	for _, trunk := range trunks {
+		if _, ok := parents[trunk]; ok {
+			problems = append(problems, Problem{Kind: ProblemTrackedTrunk, Branch: trunk,
+				Detail: fmt.Sprintf("trunk branch %s is tracked as a stacked branch", trunk)})
+		}
+	}
+
+	names := make([]string, 0, len(parents))
+	for name := range parents {
+		if !isTrunk[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !exists[name] {
+			problems = append(problems, Problem{Kind: ProblemMissingBranch, Branch: name,
+				Detail: fmt.Sprintf("%s is tracked but no longer exists", name)})
+			continue
+		}
+		parent := parents[name]
+		if _, tracked := parents[parent]; !tracked && !exists[parent] && !isTrunk[parent] {
+			problems = append(problems, Problem{Kind: ProblemMissingParent, Branch: name,
+				Detail: fmt.Sprintf("%s is stacked on %s, which does not exist", name, parent)})
+		}
+	}
+
+	for _, cycle := range findCycles(parents) {
+		problems = append(problems, Problem{Kind: ProblemCycle, Branch: cycle[0],
+			Detail: "parents form a cycle: " + strings.Join(append(cycle, cycle[0]), " → ")})
+	}
+	return problems
+}
+
+// findCycles returns each cycle in the parent links once, starting from its
+// alphabetically first branch.
+func findCycles(parents map[string]string) [][]string {
+	names := make([]string, 0, len(parents))
+	for name := range parents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	done := make(map[string]bool)
+	var cycles [][]string
+	for _, start := range names {
+		// Walk up from start until we leave tracked branches, reach a branch
+		// already explored, or come back around
+		index := make(map[string]int)
+		var path []string
+		for b := start; ; b = parents[b] {
+			if _, tracked := parents[b]; !tracked || done[b] {
+				break
+			}
+			if i, ok := index[b]; ok {
+				cycles = append(cycles, rotateToMin(path[i:]))
+				break
+			}
+			index[b] = len(path)
+			path = append(path, b)
+		}
+		for _, b := range path {
+			done[b] = true
+		}
+	}
+	return cycles
+}
+
+// rotateToMin rotates a cycle so it starts at its smallest name.
+func rotateToMin(cycle []string) []string {
+	first := 0
+	for i, name := range cycle {
+		if name < cycle[first] {
+			first = i
+		}
+	}
+	return append(append([]string{}, cycle[first:]...), cycle[:first]...)
+}
+
+// diagnoseRestack reports restack state that no longer describes a restack
+// that can be continued.
+func diagnoseRestack(parents map[string]string, exists map[string]bool) (Problem, bool) {
+	stale := Problem{Kind: ProblemStaleRestack}
+	_, headErr := git.GetRestackHead()
+	remaining, remainingErr := git.GetRestackState()
+
+	if !git.IsRestackInProgress() {
+		if headErr == nil || remainingErr == nil {
+			stale.Detail = "restack state is left over from an earlier restack"
+			return stale, true
+		}
+		return Problem{}, false
+	}
+
+	for _, name := range remaining {
+		if _, tracked := parents[name]; !tracked || !exists[name] {
+			stale.Detail = fmt.Sprintf("the restack in progress refers to %s, which is no longer tracked", name)
+			return stale, true
+		}
+	}
+	return Problem{}, false
+}
+
+// FixProblem repairs a problem found by Diagnose.
+func FixProblem(p Problem) error {
+	switch p.Kind {
+	case ProblemNoTrunk, ProblemMissingTrunk:
+		if p.Kind == ProblemMissingTrunk {
+			if err := git.RemoveTrunk(p.Branch); err != nil {
+				return err
+			}
+			if _, err := git.GetTrunks(); err == nil {
+				return nil
+			}
+		}
+		for _, name := range []string{"main", "master"} {
+			if name != p.Branch && git.BranchExists(name) {
+				return git.SetTrunk(name)
+			}
+		}
+		return fmt.Errorf("neither main nor master exists. Run 'st init --trunk <branch>'")
+
+	case ProblemTrackedTrunk:
+		return UntrackBranch(p.Branch)
+
+	case ProblemMissingBranch:
+		parents, _, _, err := loadMetadata()
+		if err != nil {
+			return err
+		}
+		for name, parent := range parents {
+			if parent == p.Branch {
+				if err := ReparentBranch(name, parents[p.Branch]); err != nil {
+					return err
+				}
+			}
+		}
+		return UntrackBranch(p.Branch)
+
+	case ProblemMissingParent, ProblemCycle:
+		trunk, err := git.GetTrunk()
+		if err != nil {
+			return err
+		}
+		return ReparentBranch(p.Branch, trunk)
+
+	case ProblemStaleRestack:
+		return git.ClearRestackState()
+	}
+	return fmt.Errorf("don't know how to fix %s", p.Kind)
+}
@@ -0,0 +1,111 @@
+package stack
+
+import (
+	"reflect"
+	"testing"
+)
+
+func kinds(problems []Problem) []string {
+	var out []string
+	for _, p := range problems {
+		out = append(out, string(p.Kind)+":"+p.Branch)
+	}
+	return out
+}
+
+func TestDiagnose_Healthy(t *testing.T) {
+	parents := map[string]string{"a": "main", "b": "a", "ext": "external/base"}
+	exists := map[string]bool{"main": true, "a": true, "b": true, "ext": true, "external/base": true}
+
+	if problems := diagnose([]string{"main"}, parents, exists); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", kinds(problems))
+	}
+}
+
+func TestDiagnose_Problems(t *testing.T) {
+	tests := []struct {
+		name    string
+		trunks  []string
+		parents map[string]string
+		exists  map[string]bool
+		want    []string
+	}{
+		{
+			name:   "no trunk",
+			exists: map[string]bool{"main": true},
+			want:   []string{"no-trunk:"},
+		},
+		{
+			name:    "missing trunk",
+			trunks:  []string{"develop"},
+			parents: map[string]string{"a": "develop"},
+			exists:  map[string]bool{"a": true},
+			want:    []string{"missing-trunk:develop"},
+		},
+		{
+			name:    "tracked trunk",
+			trunks:  []string{"main"},
+			parents: map[string]string{"main": "a", "a": "main"},
+			exists:  map[string]bool{"main": true, "a": true},
+			want:    []string{"tracked-trunk:main", "cycle:a"},
+		},
+		{
+			name:    "deleted branch",
+			trunks:  []string{"main"},
+			parents: map[string]string{"a": "main", "b": "a"},
+			exists:  map[string]bool{"main": true, "b": true},
+			want:    []string{"missing-branch:a"},
+		},
+		{
+			name:    "deleted parent",
+			trunks:  []string{"main"},
+			parents: map[string]string{"b": "gone"},
+			exists:  map[string]bool{"main": true, "b": true},
+			want:    []string{"missing-parent:b"},
+		},
+		{
+			name:    "cycle",
+			trunks:  []string{"main"},
+			parents: map[string]string{"x": "main", "c": "b", "b": "a", "a": "c"},
+			exists:  map[string]bool{"main": true, "a": true, "b": true, "c": true, "x": true},
+			want:    []string{"cycle:a"},
+		},
+		{
+			name:    "missing second trunk",
+			trunks:  []string{"main", "release/1.x"},
+			parents: map[string]string{"a": "main", "fix": "release/1.x"},
+			exists:  map[string]bool{"main": true, "a": true, "fix": true},
+			want:    []string{"missing-trunk:release/1.x"},
+		},
+		{
+			name:    "tracked second trunk",
+			trunks:  []string{"main", "release/1.x"},
+			parents: map[string]string{"release/1.x": "main", "fix": "release/1.x"},
+			exists:  map[string]bool{"main": true, "release/1.x": true, "fix": true},
+			want:    []string{"tracked-trunk:release/1.x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := kinds(diagnose(tt.trunks, tt.parents, tt.exists))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCycles(t *testing.T) {
+	parents := map[string]string{
+		"a": "b", "b": "a", // two-branch cycle
+		"self": "self",                  // parented to itself
+		"c":    "d", "d": "e", "e": "d", // tail leading into a cycle
+		"ok": "main",
+	}
+
+	got := findCycles(parents)
+	want := [][]string{{"a", "b"}, {"d", "e"}, {"self"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// FoldBranch moves branch's commits into parent by fast-forwarding it, or
+// with squash set by adding them as a single commit, then moves branch's
+// children onto parent and deletes branch, leaving parent checked out.
+// The caller restacks everything above parent.
+func FoldBranch(branch, parent *Branch, squash bool) error {
+	tip, err := git.BranchTip(branch.Name)
+	if err != nil {
+		return fmt.Errorf("could not get tip of %s: %w", branch.Name, err)
+	}
+	newTip := tip
+	if count, _ := git.CommitCount(parent.Name, branch.Name); squash && count > 0 {
+		newTip, err = git.SquashCommit(parent.Name, branch.Name)
+		if err != nil {
+			return fmt.Errorf("failed to squash %s: %w", branch.Name, err)
+		}
+	}
+
+	// Children were built on the folded branch's tip; pin that before
+	// it goes away so the restack only replays their own commits
+	for _, child := range branch.Children {
+		if err := SetBase(child.Name, tip); err != nil {
+			return fmt.Errorf("failed to record base of %s: %w", child.Name, err)
+		}
+		if err := ReparentBranch(child.Name, parent.Name); err != nil {
+			return fmt.Errorf("failed to reparent %s: %w", child.Name, err)
+		}
+	}
+
+	// The parent's other children were built on its old tip
+	for _, sibling := range parent.Children {
+		if sibling.Name != branch.Name {
+			_ = EnsureBase(sibling.Name, parent.Name)
+		}
+	}
+
+	if err := git.SetBranchTip(parent.Name, newTip); err != nil {
+		return fmt.Errorf("failed to move %s: %w", parent.Name, err)
+	}
+	if err := git.Checkout(parent.Name); err != nil {
+		return fmt.Errorf("failed to checkout %s: %w", parent.Name, err)
+	}
+
+	if err := UntrackBranch(branch.Name); err != nil {
+		return fmt.Errorf("failed to untrack branch: %w", err)
+	}
+	if err := git.DeleteBranch(branch.Name); err != nil {
+		return fmt.Errorf("failed to delete branch: %w", err)
+	}
+	return nil
+}
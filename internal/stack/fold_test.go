@@ -0,0 +1,107 @@
+package stack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// makeFoldRepo builds a → b → c, with d as a second child of a, and
+// checks out b.
+func makeFoldRepo(t *testing.T) *testRepo {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("d", "a", "d1")
+	r.branch("b", "a", "b1", "b2")
+	r.branch("c", "b", "c1")
+	r.git("checkout", "-q", "b")
+	return r
+}
+
+// foldAndRestack folds b into a as 'st fold' does.
+func foldAndRestack(r *testRepo, squash bool) {
+	r.t.Helper()
+	repo := r.load()
+	if err := FoldBranch(repo.Branches["b"], repo.Branches["a"], squash); err != nil {
+		r.t.Fatal(err)
+	}
+	if head := r.head(); head != "a" {
+		r.t.Errorf("checked out %q after folding, want a", head)
+	}
+	result, err := RestackDescendants(r.load().Branches["a"])
+	if err != nil || result.Conflict != "" {
+		r.t.Fatalf("RestackDescendants: %v, conflict %q", err, result.Conflict)
+	}
+}
+
+func TestFoldBranch(t *testing.T) {
+	r := makeFoldRepo(t)
+	tipB, tipC := r.tip("b"), r.tip("c")
+
+	foldAndRestack(r, false)
+
+	if got := r.tip("a"); got != tipB {
+		t.Errorf("a at %s, want fast-forwarded to b's tip %s", got, tipB)
+	}
+	if git.BranchExists("b") {
+		t.Error("b should have been deleted")
+	}
+	if r.parent("b") != "" {
+		t.Error("b should no longer be tracked")
+	}
+	if parent := r.parent("c"); parent != "a" {
+		t.Errorf("parent of c = %q, want a", parent)
+	}
+	if got := r.tip("c"); got != tipC {
+		t.Errorf("c moved to %s; it was already built on b's tip", got)
+	}
+	if got := r.subjects("a", "d"); !reflect.DeepEqual(got, []string{"d1"}) {
+		t.Errorf("a..d = %q, want [d1]", got)
+	}
+}
+
+func TestFoldBranch_Squash(t *testing.T) {
+	r := makeFoldRepo(t)
+	oldA, treeB := r.tip("a"), r.tip("b^{tree}")
+
+	foldAndRestack(r, true)
+
+	if got := r.subjects(oldA, "a"); len(got) != 1 {
+		t.Errorf("a gained %q, want a single squashed commit", got)
+	}
+	if got := r.tip("a^{tree}"); got != treeB {
+		t.Error("the squashed commit should have b's tree")
+	}
+	if got := r.subjects("a", "c"); !reflect.DeepEqual(got, []string{"c1"}) {
+		t.Errorf("a..c = %q, want only c's own commit", got)
+	}
+	if got := r.subjects("a", "d"); !reflect.DeepEqual(got, []string{"d1"}) {
+		t.Errorf("a..d = %q, want [d1]", got)
+	}
+}
+
+func TestFoldBranch_Undo(t *testing.T) {
+	r := makeFoldRepo(t)
+	tips := map[string]string{"a": r.tip("a"), "b": r.tip("b"), "c": r.tip("c"), "d": r.tip("d")}
+
+	r.record("fold --squash", func() error {
+		foldAndRestack(r, true)
+		return nil
+	})
+	r.undo(1)
+
+	for name, want := range tips {
+		if got := r.tip(name); got != want {
+			t.Errorf("%s at %s, want %s", name, got, want)
+		}
+	}
+	for name, want := range map[string]string{"a": "main", "b": "a", "c": "b", "d": "a"} {
+		if got := r.parent(name); got != want {
+			t.Errorf("parent of %s = %q, want %q", name, got, want)
+		}
+	}
+	if head := r.head(); head != "b" {
+		t.Errorf("checked out %q, want b", head)
+	}
+}
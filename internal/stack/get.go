@@ -0,0 +1,107 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// Where a remote branch's parent was found.
+const (
+	ParentFromMetadata    = "metadata"
+	ParentFromPullRequest = "pull request"
+	ParentFromTrunk       = "trunk"
+)
+
+// RemoteBranch is one branch of a stack being fetched from a remote.
+type RemoteBranch struct {
+	Name   string
+	Parent string
+	Base   string // recorded base from shared metadata, if any
+	Source string // ParentFromMetadata, ParentFromPullRequest or ParentFromTrunk
+}
+
+// ResolveDownstack walks down from branch to a trunk using the parents in
+// shared metadata, falling back to the base branch of its pull request
+// (prBase returns "" if there is none) and then to trunk. The walk also
+// stops at a branch already tracked locally, whose metadata is kept, and at
+// a parent with neither shared metadata nor a pull request, such as a
+// long-lived develop branch, which is not part of the stack. onRemote
+// reports whether a branch exists on the remote. Returns the branches from
+// the bottom of the stack up to branch.
+func ResolveDownstack(repo *Repo, branch string, meta map[string]Meta, prBase func(string) string, onRemote func(string) bool) ([]RemoteBranch, error) {
+	var chain []RemoteBranch
+	seen := make(map[string]bool)
+	for name := branch; !repo.IsTrunk(name); {
+		if _, tracked := repo.Branches[name]; tracked && name != branch {
+			break
+		}
+		if !onRemote(name) {
+			return nil, fmt.Errorf("branch %q does not exist on the remote", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("parents of %s form a cycle at %s", branch, name)
+		}
+		seen[name] = true
+
+		rb := RemoteBranch{Name: name, Parent: repo.Trunk, Source: ParentFromTrunk}
+		if m, ok := meta[name]; ok {
+			rb.Parent, rb.Base, rb.Source = m.Parent, m.Base, ParentFromMetadata
+		} else if base := prBase(name); base != "" {
+			rb.Parent, rb.Source = base, ParentFromPullRequest
+		} else if name != branch {
+			break
+		}
+		chain = append(chain, rb)
+		name = rb.Parent
+	}
+
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// FetchRemoteMetadata fetches the metadata published on a remote with
+// PushMetadata and returns it. A remote without any has none.
+func FetchRemoteMetadata(remote string) (map[string]Meta, error) {
+	if err := git.FetchRefs(remote, MetaRefPrefix+"*", remoteMetaPrefix(remote)+"*"); err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata from %s: %w", remote, err)
+	}
+	return RemoteStore(remote).Load()
+}
+
+// GetRemoteBranch creates a local branch tracking its remote counterpart,
+// or fast-forwards an existing one, and tracks it on rb.Parent unless it
+// is already tracked. Returns false if a local branch had diverged from
+// the remote and was left as it was.
+func GetRemoteBranch(repo *Repo, remote string, rb RemoteBranch) (bool, error) {
+	remoteRef := remote + "/" + rb.Name
+	upToDate := true
+	switch {
+	case !git.BranchExists(rb.Name):
+		if err := git.CreateBranchAt(rb.Name, remoteRef); err != nil {
+			return false, fmt.Errorf("failed to create %s: %w", rb.Name, err)
+		}
+		if err := git.SetUpstream(rb.Name, remote); err != nil {
+			return false, fmt.Errorf("failed to set upstream of %s: %w", rb.Name, err)
+		}
+	case git.IsAncestor(rb.Name, remoteRef):
+		if err := git.FastForward(rb.Name, remote); err != nil {
+			return false, fmt.Errorf("failed to fast-forward %s: %w", rb.Name, err)
+		}
+	default:
+		upToDate = git.IsAncestor(remoteRef, rb.Name)
+	}
+
+	if _, tracked := repo.Branches[rb.Name]; tracked {
+		return upToDate, nil
+	}
+	if err := TrackBranch(rb.Name, rb.Parent); err != nil {
+		return false, fmt.Errorf("failed to track %s: %w", rb.Name, err)
+	}
+	if rb.Base != "" && git.IsAncestor(rb.Base, rb.Name) {
+		return upToDate, SetBase(rb.Name, rb.Base)
+	}
+	return upToDate, EnsureBase(rb.Name, rb.Parent)
+}
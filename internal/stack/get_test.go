@@ -0,0 +1,77 @@
+package stack
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveDownstack(t *testing.T) {
+	repo := &Repo{Trunk: "main", Trunks: []string{"main"}, Branches: map[string]*Branch{
+		"local": {Name: "local", Parent: "main"},
+	}}
+	remote := map[string]bool{"a": true, "b": true, "c": true, "d": true, "local": true, "develop": true, "e": true, "f": true}
+	onRemote := func(name string) bool { return remote[name] }
+	meta := map[string]Meta{
+		"b": {Parent: "a", Base: "abc"},
+		"d": {Parent: "local"},
+		"f": {Parent: "develop"},
+	}
+	prs := map[string]string{"a": "main", "b": "main", "c": "b", "e": "develop"}
+	prBase := func(name string) string { return prs[name] }
+
+	tests := []struct {
+		branch string
+		want   []RemoteBranch
+	}{
+		{
+			branch: "c",
+			want: []RemoteBranch{
+				{Name: "a", Parent: "main", Source: ParentFromPullRequest},
+				{Name: "b", Parent: "a", Base: "abc", Source: ParentFromMetadata},
+				{Name: "c", Parent: "b", Source: ParentFromPullRequest},
+			},
+		},
+		{
+			branch: "d",
+			want:   []RemoteBranch{{Name: "d", Parent: "local", Source: ParentFromMetadata}},
+		},
+		{
+			branch: "e",
+			want:   []RemoteBranch{{Name: "e", Parent: "develop", Source: ParentFromPullRequest}},
+		},
+		{
+			branch: "f",
+			want:   []RemoteBranch{{Name: "f", Parent: "develop", Source: ParentFromMetadata}},
+		},
+		{
+			branch: "local",
+			want:   []RemoteBranch{{Name: "local", Parent: "main", Source: ParentFromTrunk}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := ResolveDownstack(repo, tt.branch, meta, prBase, onRemote)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.branch, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestResolveDownstack_Errors(t *testing.T) {
+	repo := &Repo{Trunk: "main", Trunks: []string{"main"}, Branches: map[string]*Branch{}}
+	onRemote := func(name string) bool { return name != "gone" }
+	meta := map[string]Meta{
+		"x":      {Parent: "y"},
+		"y":      {Parent: "x"},
+		"orphan": {Parent: "gone"},
+	}
+	noPR := func(string) string { return "" }
+
+	for _, branch := range []string{"x", "orphan", "gone"} {
+		if _, err := ResolveDownstack(repo, branch, meta, noPR, onRemote); err == nil {
+			t.Errorf("%s: expected an error", branch)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package stack
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// testRepo is a throwaway git repository that the test runs inside, with
+// main as its trunk.
+type testRepo struct {
+	t *testing.T
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Chdir(t.TempDir())
+	// Each test is a new repository, as each st command is a new process
+	selectedStore.resolved = false
+
+	r := &testRepo{t: t}
+	r.git("init", "-q", "-b", "main")
+	r.git("config", "user.name", "Test")
+	r.git("config", "user.email", "test@example.com")
+	r.git("config", "st.trunk", "main")
+	r.commit("init")
+	return r
+}
+
+// git runs a git command and fails the test if it errors.
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes a file named after the message and commits it.
+func (r *testRepo) commit(msg string) {
+	r.t.Helper()
+	r.commitFile(strings.ReplaceAll(msg, " ", "-")+".txt", msg+"\n", msg)
+}
+
+// commitFile writes content to file and commits it.
+func (r *testRepo) commitFile(file, content, msg string) {
+	r.t.Helper()
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+	r.git("add", "-A")
+	r.git("commit", "-q", "-m", msg)
+}
+
+// branch creates name off parent with one commit per message and tracks it,
+// leaving it checked out.
+func (r *testRepo) branch(name, parent string, msgs ...string) {
+	r.t.Helper()
+	r.git("checkout", "-q", "-b", name, parent)
+	for _, msg := range msgs {
+		r.commit(msg)
+	}
+	if err := TrackBranch(name, parent); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// tip returns the commit a ref points at.
+func (r *testRepo) tip(ref string) string {
+	r.t.Helper()
+	return r.git("rev-parse", ref)
+}
+
+// subjects lists the subjects of the commits in from..to, oldest first.
+func (r *testRepo) subjects(from, to string) []string {
+	r.t.Helper()
+	out := r.git("log", "--reverse", "--format=%s", from+".."+to)
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+// parent returns the recorded parent of a branch, or "" if it is untracked.
+func (r *testRepo) parent(name string) string {
+	parent, _ := GetParent(name)
+	return parent
+}
+
+// head returns the checked-out branch.
+func (r *testRepo) head() string {
+	r.t.Helper()
+	return r.git("branch", "--show-current")
+}
+
+// load reads the repo's stacks as a command would.
+func (r *testRepo) load() *Repo {
+	r.t.Helper()
+	repo, err := LoadRepo()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	BuildTree(repo)
+	return repo
+}
+
+// record runs fn as a command would under recordOp, logging what it changed.
+func (r *testRepo) record(command string, fn func() error) {
+	r.t.Helper()
+	before, err := CaptureState()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	if err := fn(); err != nil {
+		r.t.Fatal(err)
+	}
+	after, err := CaptureState()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	if err := RecordOperation(command, before, after); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// undo reverts the last n recorded operations.
+func (r *testRepo) undo(n int) []Operation {
+	r.t.Helper()
+	ops, err := UndoOperations(n)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return ops
+}
@@ -0,0 +1,36 @@
+package stack
+
+import (
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// IsMerged reports whether a branch has landed on target, either with its
+// commits intact (fast-forward or merge commit), squashed into a single
+// commit, or rebased commit by commit.
+func IsMerged(repo *Repo, branch *Branch, target string) bool {
+	if git.IsBranchMergedInto(branch.Name, target) {
+		return true
+	}
+	base := ForkPoint(repo, branch)
+	if base == "" {
+		return false
+	}
+	return git.IsSquashMergedInto(branch.Name, base, target) ||
+		git.IsRebaseMergedInto(branch.Name, base, target)
+}
+
+// ForkPoint returns the commit a branch's own changes start from: its
+// recorded base if any, otherwise its merge-base with its parent (or its
+// trunk if the parent no longer exists).
+func ForkPoint(repo *Repo, branch *Branch) string {
+	if base := GetBase(branch.Name); base != "" && git.IsAncestor(base, branch.Name) {
+		return base
+	}
+	if mb, err := git.MergeBase(branch.Name, branch.Parent); err == nil {
+		return mb
+	}
+	if mb, err := git.MergeBase(branch.Name, repo.TrunkOf(branch)); err == nil {
+		return mb
+	}
+	return ""
+}
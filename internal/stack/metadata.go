@@ -2,14 +2,14 @@ package stack
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/rodrigolobo/st/internal/git"
 )
 
-// LoadRepo loads the full repo state from git config.
+// LoadRepo loads the full repo state from git config and the metadata
+// store.
 func LoadRepo() (*Repo, error) {
-	trunk, err := git.GetTrunk()
+	trunks, err := git.GetTrunks()
 	if err != nil {
 		return nil, err
 	}
@@ -19,33 +19,28 @@ func LoadRepo() (*Repo, error) {
 		return nil, fmt.Errorf("could not determine current branch: %w", err)
 	}
 
-	entries, err := git.ConfigGetRegexp(`^stack\.`)
+	store, err := CurrentStore()
 	if err != nil {
-		return nil, fmt.Errorf("could not read stack config: %w", err)
+		return nil, err
+	}
+	metas, err := store.Load()
+	if err != nil {
+		return nil, err
 	}
 
 	repo := &Repo{
-		Trunk:    trunk,
+		Trunk:    trunks[0],
+		Trunks:   trunks,
 		Branches: make(map[string]*Branch),
 	}
 
-	// Parse all stack.<name>.parent entries
-	for _, entry := range entries {
-		key := entry[0]
-		value := entry[1]
-
-		// key is like "stack.feat-auth.parent"
-		parts := strings.SplitN(key, ".", 3)
-		if len(parts) != 3 || parts[2] != "parent" {
-			continue
-		}
-		branchName := parts[1]
-		parentName := value
-
+	for branchName, meta := range metas {
 		branch := &Branch{
 			Name:    branchName,
-			Parent:  parentName,
+			Parent:  meta.Parent,
+			Base:    meta.Base,
 			Current: branchName == current,
+			Frozen:  meta.Frozen,
 		}
 		repo.Branches[branchName] = branch
 	}
@@ -53,17 +48,161 @@ func LoadRepo() (*Repo, error) {
 	return repo, nil
 }
 
+// loadMetadata reads every tracked branch's parent, base and frozen flag
+// from the metadata store.
+func loadMetadata() (parents, bases map[string]string, frozen map[string]bool, err error) {
+	store, err := CurrentStore()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	metas, err := store.Load()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	parents = make(map[string]string, len(metas))
+	bases = make(map[string]string, len(metas))
+	frozen = make(map[string]bool)
+	for name, meta := range metas {
+		parents[name] = meta.Parent
+		if meta.Base != "" {
+			bases[name] = meta.Base
+		}
+		if meta.Frozen {
+			frozen[name] = true
+		}
+	}
+	return parents, bases, frozen, nil
+}
+
 // TrackBranch adds a new branch to the stack metadata.
 func TrackBranch(name, parent string) error {
-	return git.SetStackParent(name, parent)
+	store, err := CurrentStore()
+	if err != nil {
+		return err
+	}
+	return store.Write(name, Meta{Parent: parent})
 }
 
 // UntrackBranch removes a branch from the stack metadata.
 func UntrackBranch(name string) error {
-	return git.RemoveStackSection(name)
+	store, err := CurrentStore()
+	if err != nil {
+		return err
+	}
+	return store.Delete(name)
 }
 
-// ReparentBranch changes the parent of a branch.
+// ReparentBranch changes the parent of a branch, tracking it if needed.
 func ReparentBranch(name, newParent string) error {
-	return git.SetStackParent(name, newParent)
+	store, err := CurrentStore()
+	if err != nil {
+		return err
+	}
+	meta, _, err := store.Read(name)
+	if err != nil {
+		return err
+	}
+	meta.Parent = newParent
+	return store.Write(name, meta)
+}
+
+// GetParent returns the parent of a tracked branch.
+func GetParent(name string) (string, error) {
+	store, err := CurrentStore()
+	if err != nil {
+		return "", err
+	}
+	meta, ok, err := store.Read(name)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("branch %q is not tracked by st", name)
+	}
+	return meta.Parent, nil
+}
+
+// SetFrozen freezes or unfreezes a tracked branch. st never rebases, amends,
+// folds or pushes a frozen branch.
+func SetFrozen(name string, frozen bool) error {
+	store, err := CurrentStore()
+	if err != nil {
+		return err
+	}
+	meta, ok, err := store.Read(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("branch %q is not tracked by st", name)
+	}
+	meta.Frozen = frozen
+	return store.Write(name, meta)
+}
+
+// IsFrozen reports whether a tracked branch is frozen.
+func IsFrozen(name string) bool {
+	meta, ok := readMeta(name)
+	return ok && meta.Frozen
+}
+
+// GetBase returns the parent tip a branch was last built on, or "" if none
+// is recorded.
+func GetBase(name string) string {
+	meta, _ := readMeta(name)
+	return meta.Base
+}
+
+// readMeta returns a branch's metadata, and false if it is untracked or
+// can't be read.
+func readMeta(name string) (Meta, bool) {
+	store, err := CurrentStore()
+	if err != nil {
+		return Meta{}, false
+	}
+	meta, ok, err := store.Read(name)
+	if err != nil {
+		return Meta{}, false
+	}
+	return meta, ok
+}
+
+// SetBase records the parent tip a branch was last built on.
+func SetBase(name, sha string) error {
+	return setBase(name, sha)
+}
+
+// UnsetBase forgets the recorded base of a branch.
+func UnsetBase(name string) error {
+	return setBase(name, "")
+}
+
+func setBase(name, sha string) error {
+	store, err := CurrentStore()
+	if err != nil {
+		return err
+	}
+	meta, ok, err := store.Read(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("branch %q is not tracked by st", name)
+	}
+	meta.Base = sha
+	return store.Write(name, meta)
+}
+
+// EnsureBase records where a branch currently forks from parent as its
+// base, unless it already has a base that is still in its history.
+func EnsureBase(name, parent string) error {
+	if base := GetBase(name); base != "" && git.IsAncestor(base, name) {
+		return nil
+	}
+	mb, err := git.MergeBase(name, parent)
+	if err != nil {
+		return err
+	}
+	return SetBase(name, mb)
 }
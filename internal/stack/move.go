@@ -0,0 +1,92 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// MoveBranch makes onto the parent of branch, recording where the branch's
+// own commits start so a restack replays only those. The caller restacks.
+func MoveBranch(repo *Repo, branch *Branch, onto string) error {
+	if err := validateMove(repo, branch, onto); err != nil {
+		return err
+	}
+	if !git.BranchExists(onto) {
+		return fmt.Errorf("branch %q does not exist", onto)
+	}
+	if err := EnsureBase(branch.Name, branch.Parent); err != nil {
+		return fmt.Errorf("failed to record base: %w", err)
+	}
+	if err := ReparentBranch(branch.Name, onto); err != nil {
+		return fmt.Errorf("failed to reparent: %w", err)
+	}
+	branch.Parent = onto
+	return nil
+}
+
+// InsertBranch tracks name, a branch just created on top of parent, and
+// moves children (branches stacked on parent) onto it, recording where
+// their own commits start. The caller restacks the children. Returns the
+// new branch, linked into repo.
+func InsertBranch(repo *Repo, name, parent string, children []*Branch) (*Branch, error) {
+	if err := TrackBranch(name, parent); err != nil {
+		return nil, fmt.Errorf("failed to track branch: %w", err)
+	}
+	tip, err := git.BranchTip(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := SetBase(name, tip); err != nil {
+		return nil, fmt.Errorf("failed to record base: %w", err)
+	}
+
+	branch := &Branch{Name: name, Parent: parent, Base: tip}
+	moved := make(map[*Branch]bool, len(children))
+	for _, child := range children {
+		if err := EnsureBase(child.Name, parent); err != nil {
+			return nil, fmt.Errorf("failed to record base of %s: %w", child.Name, err)
+		}
+		if err := ReparentBranch(child.Name, name); err != nil {
+			return nil, fmt.Errorf("failed to reparent %s: %w", child.Name, err)
+		}
+		child.Parent = name
+		branch.Children = append(branch.Children, child)
+		moved[child] = true
+	}
+
+	repo.Branches[name] = branch
+	if p, ok := repo.Branches[parent]; ok {
+		p.Children = append(unmoved(p.Children, moved), branch)
+	} else {
+		repo.Stacks = append(unmoved(repo.Stacks, moved), branch)
+	}
+	return branch, nil
+}
+
+func unmoved(branches []*Branch, moved map[*Branch]bool) []*Branch {
+	var kept []*Branch
+	for _, b := range branches {
+		if !moved[b] {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+// validateMove rejects moving trunk, moving a branch onto itself, and moving
+// it onto anything stacked above it, which would create a cycle.
+func validateMove(repo *Repo, branch *Branch, onto string) error {
+	if repo.IsTrunk(branch.Name) {
+		return fmt.Errorf("cannot move trunk branch")
+	}
+	if onto == branch.Name {
+		return fmt.Errorf("cannot move a branch onto itself")
+	}
+	for _, b := range AllBranchesInStack(branch) {
+		if b.Name == onto {
+			return fmt.Errorf("cannot move %s onto %s: %s is stacked on top of it", branch.Name, onto, onto)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package stack
+
+import (
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+func TestValidateMove(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"a":     "main",
+		"b":     "a",
+		"c":     "b",
+		"other": "main",
+	}, "")
+	BuildTree(repo)
+
+	tests := []struct {
+		branch, onto string
+		want         string // substring of the error, or "" for none
+	}{
+		{"b", "other", ""},
+		{"b", "main", ""},
+		{"c", "a", ""},
+		{"b", "b", "onto itself"},
+		{"a", "c", "stacked on top"},
+		{"a", "b", "stacked on top"},
+	}
+	for _, tt := range tests {
+		err := validateMove(repo, repo.Branches[tt.branch], tt.onto)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("move %s onto %s: unexpected error: %v", tt.branch, tt.onto, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("move %s onto %s: expected error containing %q, got %v", tt.branch, tt.onto, tt.want, err)
+		}
+	}
+}
+
+// moveAndRestack moves name onto onto and restacks its subtree as 'st move'
+// does.
+func moveAndRestack(r *testRepo, name, onto string) *RestackResult {
+	r.t.Helper()
+	repo := r.load()
+	branch := repo.Branches[name]
+	if err := MoveBranch(repo, branch, onto); err != nil {
+		r.t.Fatal(err)
+	}
+	result, err := RestackSubtree(branch)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return result
+}
+
+func TestMoveBranch_Subtree(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a", "b1", "b2")
+	r.branch("c", "b", "c1")
+	r.branch("other", "main", "other1")
+	tipA := r.tip("a")
+
+	result := moveAndRestack(r, "b", "other")
+
+	if result.Conflict != "" || !reflect.DeepEqual(result.Rebased, []string{"b", "c"}) {
+		t.Fatalf("got %+v, want b and c rebased", result)
+	}
+	for name, want := range map[string]struct{ parent, base string }{
+		"b": {"other", r.tip("other")},
+		"c": {"b", r.tip("b")},
+	} {
+		if parent := r.parent(name); parent != want.parent {
+			t.Errorf("parent of %s = %q, want %q", name, parent, want.parent)
+		}
+		if base := GetBase(name); base != want.base {
+			t.Errorf("base of %s = %s, want %s", name, base, want.base)
+		}
+	}
+	if got := r.subjects("other", "b"); !reflect.DeepEqual(got, []string{"b1", "b2"}) {
+		t.Errorf("other..b = %q, want [b1 b2]", got)
+	}
+	if got := r.subjects("b", "c"); !reflect.DeepEqual(got, []string{"c1"}) {
+		t.Errorf("b..c = %q, want [c1]", got)
+	}
+	if r.tip("a") != tipA {
+		t.Error("a should be left alone")
+	}
+}
+
+func TestMoveBranch_Conflict(t *testing.T) {
+	r := newTestRepo(t)
+	t.Setenv("GIT_EDITOR", "true")
+	r.branch("a", "main")
+	r.commitFile("f.txt", "one\n", "a1")
+	r.branch("b", "a")
+	r.commitFile("f.txt", "two\n", "b1")
+	r.branch("c", "b", "c1")
+	r.branch("other", "main")
+	r.commitFile("f.txt", "three\n", "other1")
+
+	result := moveAndRestack(r, "b", "other")
+
+	if result.Conflict != "b" {
+		t.Fatalf("expected a conflict on b, got %+v", result)
+	}
+	if !git.IsRebaseInProgress() || !git.IsRestackInProgress() {
+		t.Fatal("the restack should be left in progress for 'st continue'")
+	}
+	if queue, _ := git.GetRestackState(); !slices.Equal(queue, []string{"b", "c"}) {
+		t.Errorf("saved queue = %q, want [b c]", queue)
+	}
+	if parent := r.parent("b"); parent != "other" {
+		t.Errorf("parent of b = %q, want other", parent)
+	}
+
+	// Resolve and continue as 'st continue' does
+	if err := os.WriteFile("f.txt", []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r.git("add", "f.txt")
+	if err := git.RebaseContinue(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := RestackRemaining()
+	if err != nil || result.Conflict != "" {
+		t.Fatalf("RestackRemaining: %v, conflict %q", err, result.Conflict)
+	}
+	if git.IsRestackInProgress() {
+		t.Error("the restack state should be cleared once the queue is done")
+	}
+	if got := r.subjects("other", "b"); !reflect.DeepEqual(got, []string{"b1"}) {
+		t.Errorf("other..b = %q, want [b1]", got)
+	}
+	if got := r.subjects("b", "c"); !reflect.DeepEqual(got, []string{"c1"}) {
+		t.Errorf("b..c = %q, want [c1]", got)
+	}
+}
+
+func TestInsertBranch(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a", "b1")
+	r.branch("c", "a", "c1")
+	r.git("checkout", "-q", "a")
+	tipA, tipB, tipC := r.tip("a"), r.tip("b"), r.tip("c")
+
+	r.record("create n --insert", func() error {
+		repo := r.load()
+		r.git("checkout", "-q", "-b", "n")
+		n, err := InsertBranch(repo, "n", "a", []*Branch{repo.Branches["b"]})
+		if err != nil {
+			return err
+		}
+		var children []string
+		for _, child := range repo.Branches["a"].Children {
+			children = append(children, child.Name)
+		}
+		if !reflect.DeepEqual(children, []string{"c", "n"}) || len(n.Children) != 1 || n.Children[0].Name != "b" {
+			t.Errorf("a has children %q and n has %d; want [c n] and [b]", children, len(n.Children))
+		}
+
+		r.commit("n1")
+		result, err := RestackDescendants(n)
+		if err == nil && result.Conflict != "" {
+			t.Fatalf("conflict on %s", result.Conflict)
+		}
+		return err
+	})
+
+	for name, want := range map[string]string{"n": "a", "b": "n", "c": "a"} {
+		if got := r.parent(name); got != want {
+			t.Errorf("parent of %s = %q, want %q", name, got, want)
+		}
+	}
+	if got := GetBase("n"); got != tipA {
+		t.Errorf("base of n = %s, want the tip of a", got)
+	}
+	if got := r.subjects("a", "b"); !reflect.DeepEqual(got, []string{"n1", "b1"}) {
+		t.Errorf("a..b = %q, want [n1 b1]", got)
+	}
+	if got := GetBase("b"); got != r.tip("n") {
+		t.Errorf("base of b = %s, want the tip of n", got)
+	}
+	if r.tip("a") != tipA || r.tip("c") != tipC {
+		t.Error("a and c should be left alone")
+	}
+
+	r.undo(1)
+	if git.BranchExists("n") {
+		t.Error("n should be gone after undo")
+	}
+	if parent := r.parent("b"); parent != "a" || r.tip("b") != tipB {
+		t.Errorf("b on %q at %s after undo, want a at %s", parent, r.tip("b"), tipB)
+	}
+}
@@ -0,0 +1,351 @@
+package stack
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// State captures branch tips and stack metadata at a point in time.
+type State struct {
+	Head    string            `json:"head,omitempty"`
+	Tips    map[string]string `json:"tips"`            // branch -> SHA, for every local branch
+	Parents map[string]string `json:"parents"`         // branch -> parent, for every tracked branch
+	Bases   map[string]string `json:"bases,omitempty"` // branch -> recorded base, where known
+	Frozen  map[string]bool   `json:"frozen"`          // frozen tracked branches; nil in entries that predate freezing
+}
+
+// Operation is a single entry in the operation log.
+type Operation struct {
+	ID      int       `json:"id"`
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+	Before  State     `json:"before"`
+	After   State     `json:"after"`
+}
+
+// CaptureState reads the current branch tips and stack metadata.
+func CaptureState() (*State, error) {
+	tips, err := git.BranchTips()
+	if err != nil {
+		return nil, fmt.Errorf("could not read branch tips: %w", err)
+	}
+	parents, bases, frozen, err := loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	head, _ := git.CurrentBranch()
+	return &State{Head: head, Tips: tips, Parents: parents, Bases: bases, Frozen: frozen}, nil
+}
+
+// Changed reports whether any branch tip, parent or frozen flag differs
+// between two states.
+func (s *State) Changed(other *State) bool {
+	return !reflect.DeepEqual(s.Tips, other.Tips) || !reflect.DeepEqual(s.Parents, other.Parents) ||
+		len(frozenChanges(s, other)) > 0
+}
+
+// frozenChanges returns the branches frozen or unfrozen between two states.
+func frozenChanges(before, after *State) []string {
+	var names []string
+	for _, m := range []map[string]bool{before.Frozen, after.Frozen} {
+		for name := range m {
+			if before.Frozen[name] != after.Frozen[name] && !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Changes describes what an operation did, one line per affected branch.
+func (op *Operation) Changes() []string {
+	names := touchedBranches([]Operation{*op})
+
+	var changes []string
+	for _, name := range names {
+		before, hadBefore := op.Before.Tips[name]
+		after, hasAfter := op.After.Tips[name]
+		switch {
+		case !hadBefore && hasAfter:
+			changes = append(changes, fmt.Sprintf("%s: created at %s", name, short(after)))
+		case hadBefore && !hasAfter:
+			changes = append(changes, fmt.Sprintf("%s: deleted (was %s)", name, short(before)))
+		case before != after:
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", name, short(before), short(after)))
+		}
+
+		oldParent, wasTracked := op.Before.Parents[name]
+		newParent, isTracked := op.After.Parents[name]
+		switch {
+		case !wasTracked && isTracked:
+			changes = append(changes, fmt.Sprintf("%s: tracked (parent: %s)", name, newParent))
+		case wasTracked && !isTracked:
+			changes = append(changes, fmt.Sprintf("%s: untracked (parent was %s)", name, oldParent))
+		case oldParent != newParent:
+			changes = append(changes, fmt.Sprintf("%s: parent %s → %s", name, oldParent, newParent))
+		}
+
+		switch wasFrozen, isFrozen := op.Before.Frozen[name], op.After.Frozen[name]; {
+		case !wasFrozen && isFrozen:
+			changes = append(changes, fmt.Sprintf("%s: frozen", name))
+		case wasFrozen && !isFrozen && isTracked:
+			changes = append(changes, fmt.Sprintf("%s: unfrozen", name))
+		}
+	}
+	return changes
+}
+
+// RecordOperation appends an operation to the log if it changed anything.
+func RecordOperation(command string, before, after *State) error {
+	if !before.Changed(after) {
+		return nil
+	}
+
+	ops, err := ReadOperations()
+	if err != nil {
+		return err
+	}
+	id := 1
+	if len(ops) > 0 {
+		id = ops[len(ops)-1].ID + 1
+	}
+
+	path, err := oplogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(Operation{
+		ID:      id,
+		Command: command,
+		Time:    time.Now(),
+		Before:  *before,
+		After:   *after,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// ReadOperations reads the operation log, oldest first.
+func ReadOperations() ([]Operation, error) {
+	path, err := oplogPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ops []Operation
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var op Operation
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			return nil, fmt.Errorf("corrupt operation log %s: %w", path, err)
+		}
+		ops = append(ops, op)
+	}
+	return ops, scanner.Err()
+}
+
+// UndoOperations reverts the n most recent operations by restoring every
+// branch they touched to its tip and parent from before the oldest of them.
+// Returns the operations that were reverted, newest first.
+func UndoOperations(n int) ([]Operation, error) {
+	ops, err := ReadOperations()
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 || n > len(ops) {
+		return nil, fmt.Errorf("only %d operation(s) in the log", len(ops))
+	}
+	if git.IsRestackInProgress() || git.IsRebaseInProgress() {
+		return nil, fmt.Errorf("a restack is in progress. Run 'st continue' or 'st abort' first")
+	}
+	if git.HasUncommittedChanges() {
+		return nil, fmt.Errorf("you have uncommitted changes. Commit or stash them first")
+	}
+
+	undone := ops[len(ops)-n:]
+	target := undone[0].Before
+	if err := restoreState(&target, touchedBranches(undone)); err != nil {
+		return nil, err
+	}
+
+	reverted := make([]Operation, 0, n)
+	for i := len(undone) - 1; i >= 0; i-- {
+		reverted = append(reverted, undone[i])
+	}
+	return reverted, nil
+}
+
+// restoreState puts the named branches back to their tips, parents, bases
+// and frozen flags in target.
+func restoreState(target *State, names []string) error {
+	current, _ := git.CurrentBranch()
+	parents, bases, _, err := loadMetadata()
+	if err != nil {
+		return err
+	}
+
+	// Move or recreate branches first, leaving the checked-out one for last
+	for _, name := range names {
+		sha, ok := target.Tips[name]
+		if !ok || name == current {
+			continue
+		}
+		if tip, err := git.BranchTip(name); err == nil && tip == sha {
+			continue
+		}
+		if err := git.SetBranchTip(name, sha); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", name, err)
+		}
+	}
+	if sha, ok := target.Tips[current]; ok && contains(names, current) {
+		if tip, _ := git.BranchTip(current); tip != sha {
+			if err := git.ResetHard(sha); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", current, err)
+			}
+		}
+	}
+
+	// Return to the branch that was checked out before, or trunk if the
+	// current branch is about to be deleted
+	head := current
+	if _, ok := target.Tips[target.Head]; ok {
+		head = target.Head
+	} else if _, ok := target.Tips[current]; !ok && contains(names, current) {
+		head, _ = git.GetTrunk()
+	}
+	if head != "" && head != current {
+		if err := git.Checkout(head); err != nil {
+			return fmt.Errorf("failed to checkout %s: %w", head, err)
+		}
+	}
+
+	// Delete branches that did not exist before
+	for _, name := range names {
+		if _, ok := target.Tips[name]; ok || !git.BranchExists(name) {
+			continue
+		}
+		if err := git.DeleteBranch(name); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", name, err)
+		}
+	}
+
+	// Restore metadata
+	for _, name := range names {
+		parent, wasTracked := target.Parents[name]
+		existing, isTracked := parents[name]
+		switch {
+		case wasTracked && (!isTracked || existing != parent):
+			if err := ReparentBranch(name, parent); err != nil {
+				return fmt.Errorf("failed to restore parent of %s: %w", name, err)
+			}
+		case !wasTracked && isTracked:
+			if err := UntrackBranch(name); err != nil {
+				return fmt.Errorf("failed to untrack %s: %w", name, err)
+			}
+			continue
+		}
+
+		if base := target.Bases[name]; wasTracked && base != "" && base != bases[name] {
+			if err := SetBase(name, base); err != nil {
+				return fmt.Errorf("failed to restore base of %s: %w", name, err)
+			}
+		}
+
+		if frozen := target.Frozen[name]; wasTracked && target.Frozen != nil && frozen != IsFrozen(name) {
+			if err := SetFrozen(name, frozen); err != nil {
+				return fmt.Errorf("failed to restore frozen flag of %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// touchedBranches returns the sorted names of every branch whose tip, parent
+// or frozen flag changed in any of the given operations.
+func touchedBranches(ops []Operation) []string {
+	seen := make(map[string]bool)
+	for _, op := range ops {
+		for _, m := range []map[string]string{op.Before.Tips, op.After.Tips} {
+			for name := range m {
+				if op.Before.Tips[name] != op.After.Tips[name] {
+					seen[name] = true
+				}
+			}
+		}
+		for _, m := range []map[string]string{op.Before.Parents, op.After.Parents} {
+			for name := range m {
+				before, wasTracked := op.Before.Parents[name]
+				after, isTracked := op.After.Parents[name]
+				if wasTracked != isTracked || before != after {
+					seen[name] = true
+				}
+			}
+		}
+		for _, name := range frozenChanges(&op.Before, &op.After) {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func oplogPath() (string, error) {
+	dir, err := git.CommonDir()
+	if err != nil {
+		return "", fmt.Errorf("could not locate git directory: %w", err)
+	}
+	return filepath.Join(dir, "st", "oplog"), nil
+}
+
+func short(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
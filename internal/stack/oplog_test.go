@@ -0,0 +1,199 @@
+package stack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+func TestOperationChanges(t *testing.T) {
+	op := &Operation{
+		Before: State{
+			Tips:    map[string]string{"main": "m1", "a": "a1111111", "b": "b1111111"},
+			Parents: map[string]string{"a": "main", "b": "a"},
+		},
+		After: State{
+			Tips:    map[string]string{"main": "m1", "b": "b2222222", "c": "c1111111"},
+			Parents: map[string]string{"b": "main", "c": "b"},
+		},
+	}
+
+	got := op.Changes()
+	expected := []string{
+		"a: deleted (was a111111)",
+		"a: untracked (parent was main)",
+		"b: b111111 → b222222",
+		"b: parent a → main",
+		"c: created at c111111",
+		"c: tracked (parent: b)",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected changes:\n got: %q\nwant: %q", got, expected)
+	}
+}
+
+func TestTouchedBranches_UnionAcrossOperations(t *testing.T) {
+	ops := []Operation{
+		{
+			Before: State{Tips: map[string]string{"a": "1"}, Parents: map[string]string{}},
+			After:  State{Tips: map[string]string{"a": "2"}, Parents: map[string]string{}},
+		},
+		{
+			Before: State{Tips: map[string]string{"a": "2"}, Parents: map[string]string{"b": "a"}},
+			After:  State{Tips: map[string]string{"a": "2"}, Parents: map[string]string{"b": "main"}},
+		},
+	}
+
+	got := touchedBranches(ops)
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", got)
+	}
+}
+
+func TestStateChanged_HeadOnly(t *testing.T) {
+	before := &State{Head: "a", Tips: map[string]string{"a": "1"}, Parents: map[string]string{"a": "main"}}
+	after := &State{Head: "main", Tips: map[string]string{"a": "1"}, Parents: map[string]string{"a": "main"}}
+	if before.Changed(after) {
+		t.Error("switching branches alone should not count as a change")
+	}
+}
+
+func TestOperationChanges_Frozen(t *testing.T) {
+	op := &Operation{
+		Before: State{
+			Tips:    map[string]string{"a": "1", "b": "2", "c": "3"},
+			Parents: map[string]string{"a": "main", "b": "a", "c": "b"},
+			Frozen:  map[string]bool{"b": true, "c": true},
+		},
+		After: State{
+			Tips:    map[string]string{"a": "1", "b": "2", "c": "3"},
+			Parents: map[string]string{"a": "main", "b": "a"},
+			Frozen:  map[string]bool{"a": true},
+		},
+	}
+
+	if !op.Before.Changed(&op.After) {
+		t.Error("freezing a branch should count as a change")
+	}
+	got := op.Changes()
+	expected := []string{"a: frozen", "b: unfrozen", "c: untracked (parent was b)"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected changes:\n got: %q\nwant: %q", got, expected)
+	}
+}
+
+func TestUndoOperations_RestoresFrozen(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.git("checkout", "-q", "main")
+	if err := SetFrozen("a", true); err != nil {
+		t.Fatal(err)
+	}
+
+	r.record("untrack a", func() error { return UntrackBranch("a") })
+	r.undo(1)
+	if parent, _ := GetParent("a"); parent != "main" || !IsFrozen("a") {
+		t.Errorf("after undoing untrack: parent %q, frozen %v; want main, true", parent, IsFrozen("a"))
+	}
+
+	r.record("unfreeze a", func() error { return SetFrozen("a", false) })
+	r.undo(1)
+	if !IsFrozen("a") {
+		t.Error("undoing unfreeze should freeze a again")
+	}
+}
+
+func TestUndoOperations_RestoresDeletedBranch(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a", "b1")
+	r.git("checkout", "-q", "main")
+	tipA, tipB := r.tip("a"), r.tip("b")
+
+	r.record("delete a", func() error {
+		if err := ReparentBranch("b", "main"); err != nil {
+			return err
+		}
+		if err := UntrackBranch("a"); err != nil {
+			return err
+		}
+		return git.DeleteBranch("a")
+	})
+	r.undo(1)
+
+	if got := r.tip("a"); got != tipA {
+		t.Errorf("a restored at %s, want %s", got, tipA)
+	}
+	if got := r.tip("b"); got != tipB {
+		t.Errorf("b moved to %s, want %s", got, tipB)
+	}
+	if got := r.parent("a"); got != "main" {
+		t.Errorf("parent of a = %q, want main", got)
+	}
+	if got := r.parent("b"); got != "a" {
+		t.Errorf("parent of b = %q, want a", got)
+	}
+}
+
+func TestUndoOperations_ResetsRestackedBranches(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a", "b1")
+	if err := EnsureBase("b", "a"); err != nil {
+		t.Fatal(err)
+	}
+	tipA, tipB, baseB := r.tip("a"), r.tip("b"), GetBase("b")
+
+	r.record("modify", func() error {
+		r.git("checkout", "-q", "a")
+		r.commit("a2")
+		result, err := RestackAll(r.load())
+		if err == nil && len(result.Rebased) != 1 {
+			t.Errorf("expected b to be rebased, got %+v", result)
+		}
+		return err
+	})
+	if GetBase("b") == baseB {
+		t.Fatal("restack should have moved the base of b")
+	}
+
+	ops := r.undo(1)
+	if len(ops) != 1 || ops[0].Command != "modify" {
+		t.Errorf("undid %+v, want the modify", ops)
+	}
+	if got := r.tip("a"); got != tipA {
+		t.Errorf("a reset to %s, want %s", got, tipA)
+	}
+	if got := r.tip("b"); got != tipB {
+		t.Errorf("b reset to %s, want %s", got, tipB)
+	}
+	if got := GetBase("b"); got != baseB {
+		t.Errorf("base of b = %s, want %s", got, baseB)
+	}
+	if head := r.head(); head != "b" {
+		t.Errorf("checked out %s, want b, as before the modify", head)
+	}
+}
+
+func TestUndoOperations_DeletesCreatedBranch(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.git("checkout", "-q", "main")
+
+	r.record("create b", func() error {
+		r.branch("b", "a", "b1")
+		return nil
+	})
+	r.undo(1)
+
+	if git.BranchExists("b") {
+		t.Error("b should have been deleted")
+	}
+	if got := r.parent("b"); got != "" {
+		t.Errorf("b should no longer be tracked, but has parent %q", got)
+	}
+	if head := r.head(); head != "main" {
+		t.Errorf("checked out %s, want main", head)
+	}
+}
@@ -0,0 +1,106 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// ValidateRename checks that the tracked branch oldName can be renamed. A
+// frozen branch can be renamed locally, but not on the remote, since that
+// moves its pull request.
+func ValidateRename(repo *Repo, oldName string, remote bool) error {
+	if repo.IsTrunk(oldName) {
+		return fmt.Errorf("cannot rename trunk branch")
+	}
+	branch, ok := repo.Branches[oldName]
+	if !ok {
+		return fmt.Errorf("branch %q is not tracked by st", oldName)
+	}
+	if remote && branch.Frozen {
+		return fmt.Errorf("branch %q is frozen. Run 'st unfreeze %s' first", oldName, oldName)
+	}
+	return nil
+}
+
+// RenameBranch renames a tracked branch, moving its metadata to the new
+// name and pointing its children and any restack in progress at it.
+func RenameBranch(branch *Branch, newName string) error {
+	store, err := CurrentStore()
+	if err != nil {
+		return err
+	}
+	oldName := branch.Name
+	if err := git.RenameBranch(oldName, newName); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", oldName, err)
+	}
+
+	meta, _, err := store.Read(oldName)
+	if err != nil {
+		return err
+	}
+	if err := store.Write(newName, meta); err != nil {
+		return fmt.Errorf("failed to move metadata of %s: %w", oldName, err)
+	}
+	if err := store.Delete(oldName); err != nil {
+		return fmt.Errorf("failed to remove old metadata of %s: %w", oldName, err)
+	}
+
+	for _, child := range branch.Children {
+		if err := ReparentBranch(child.Name, newName); err != nil {
+			return fmt.Errorf("failed to reparent %s: %w", child.Name, err)
+		}
+	}
+
+	if err := renameInRestackState(oldName, newName); err != nil {
+		return fmt.Errorf("failed to update restack state: %w", err)
+	}
+	branch.Name = newName
+	return nil
+}
+
+// renameInRestackState replaces a branch's old name in the saved state of a
+// restack in progress, so 'st continue' and 'st abort' still find it.
+func renameInRestackState(oldName, newName string) error {
+	if remaining, err := git.GetRestackState(); err == nil {
+		if renameInList(remaining, oldName, newName) {
+			if err := git.SetRestackState(remaining); err != nil {
+				return err
+			}
+		}
+	}
+
+	tips, bases, err := git.GetRestackSnapshot()
+	if err != nil {
+		return nil // no snapshot
+	}
+	head, _ := git.GetRestackHead()
+	if _, ok := tips[oldName]; !ok && head != oldName {
+		return nil
+	}
+	if sha, ok := tips[oldName]; ok {
+		tips[newName] = sha
+		delete(tips, oldName)
+	}
+	if base, ok := bases[oldName]; ok {
+		bases[newName] = base
+		delete(bases, oldName)
+	}
+	if head == oldName {
+		head = newName
+	}
+	return git.SetRestackSnapshot(head, tips, bases)
+}
+
+// renameInList replaces oldName with newName in a list of branch names,
+// reporting whether it was there.
+func renameInList(names []string, oldName, newName string) bool {
+	found := false
+	for i, name := range names {
+		if name == oldName {
+			names[i] = newName
+			found = true
+		}
+	}
+	return found
+}
@@ -0,0 +1,139 @@
+package stack
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+func TestRenameInList(t *testing.T) {
+	tests := []struct {
+		list, want []string
+		found      bool
+	}{
+		{[]string{"feat"}, []string{"feature"}, true},
+		{[]string{"a", "feat", "b"}, []string{"a", "feature", "b"}, true},
+		{[]string{"a", "feat-2", "b"}, []string{"a", "feat-2", "b"}, false},
+		{[]string{"feat,x"}, []string{"feat,x"}, false},
+		{nil, nil, false},
+	}
+	for _, tt := range tests {
+		got := slices.Clone(tt.list)
+		if found := renameInList(got, "feat", "feature"); found != tt.found || !slices.Equal(got, tt.want) {
+			t.Errorf("renameInList(%q) = %q, %v, want %q, %v", tt.list, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestValidateRename(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"a":      "main",
+		"frozen": "a",
+	}, "")
+	BuildTree(repo)
+	repo.Branches["frozen"].Frozen = true
+
+	tests := []struct {
+		name   string
+		remote bool
+		want   string // substring of the error, or "" for none
+	}{
+		{"a", false, ""},
+		{"a", true, ""},
+		{"frozen", false, ""},
+		{"frozen", true, "is frozen"},
+		{"main", false, "trunk"},
+		{"untracked", false, "not tracked"},
+	}
+	for _, tt := range tests {
+		err := ValidateRename(repo, tt.name, tt.remote)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("rename %s (remote %v): unexpected error: %v", tt.name, tt.remote, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("rename %s (remote %v): expected error containing %q, got %v", tt.name, tt.remote, tt.want, err)
+		}
+	}
+}
+
+func TestRenameBranch(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a", "b1")
+	r.branch("c", "b", "c1")
+	r.git("checkout", "-q", "b")
+	if err := EnsureBase("b", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFrozen("b", true); err != nil {
+		t.Fatal(err)
+	}
+	tipB, baseB := r.tip("b"), GetBase("b")
+
+	r.record("rename b2", func() error {
+		return RenameBranch(r.load().Branches["b"], "b2")
+	})
+
+	if git.BranchExists("b") {
+		t.Error("b should have been renamed away")
+	}
+	if got := r.tip("b2"); got != tipB {
+		t.Errorf("b2 at %s, want %s", got, tipB)
+	}
+	if head := r.head(); head != "b2" {
+		t.Errorf("checked out %q, want b2", head)
+	}
+	if parent := r.parent("b2"); parent != "a" || GetBase("b2") != baseB || !IsFrozen("b2") {
+		t.Errorf("b2: parent %q, base %s, frozen %v; want a, %s, true", parent, GetBase("b2"), IsFrozen("b2"), baseB)
+	}
+	if r.parent("b") != "" {
+		t.Error("b should no longer be tracked")
+	}
+	if parent := r.parent("c"); parent != "b2" {
+		t.Errorf("parent of c = %q, want b2", parent)
+	}
+
+	r.undo(1)
+	if git.BranchExists("b2") {
+		t.Error("b2 should be gone after undo")
+	}
+	if got := r.tip("b"); got != tipB {
+		t.Errorf("b at %s after undo, want %s", got, tipB)
+	}
+	if parent := r.parent("b"); parent != "a" || GetBase("b") != baseB || !IsFrozen("b") {
+		t.Errorf("b after undo: parent %q, base %s, frozen %v; want a, %s, true", parent, GetBase("b"), IsFrozen("b"), baseB)
+	}
+	if parent := r.parent("c"); parent != "b" {
+		t.Errorf("parent of c = %q after undo, want b", parent)
+	}
+}
+
+func TestRenameBranch_DuringRestack(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a", "b1")
+	tipA, tipB := r.tip("a"), r.tip("b")
+	if err := git.SetRestackState([]string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := git.SetRestackSnapshot("b", map[string]string{"a": tipA, "b": tipB}, map[string]string{"b": tipA}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RenameBranch(r.load().Branches["b"], "b2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if remaining, _ := git.GetRestackState(); !slices.Equal(remaining, []string{"a", "b2"}) {
+		t.Errorf("restack queue = %q, want [a b2]", remaining)
+	}
+	tips, bases, _ := git.GetRestackSnapshot()
+	if tips["b2"] != tipB || bases["b2"] != tipA || tips["b"] != "" {
+		t.Errorf("snapshot tips %v, bases %v; want b renamed to b2", tips, bases)
+	}
+	if head, _ := git.GetRestackHead(); head != "b2" {
+		t.Errorf("restack head = %q, want b2", head)
+	}
+}
@@ -2,7 +2,7 @@ package stack
 
 import (
 	"fmt"
-	"strings"
+	"sort"
 
 	"github.com/rodrigolobo/st/internal/git"
 )
@@ -11,21 +11,17 @@ import (
 type RestackResult struct {
 	Rebased  []string // branches that were rebased
 	Skipped  []string // branches that were already up to date
+	Frozen   []string // frozen branches that were left alone
 	Conflict string   // branch where a conflict occurred (empty if none)
 }
 
 // RestackAll restacks all stacks in the repo.
 func RestackAll(repo *Repo) (*RestackResult, error) {
-	result := &RestackResult{}
-	for _, root := range repo.Stacks {
-		if err := restackBranch(root, repo.Trunk, result); err != nil {
-			return result, err
-		}
-		if result.Conflict != "" {
-			return result, nil
-		}
+	var branches []*Branch
+	for _, b := range repo.Branches {
+		branches = append(branches, b)
 	}
-	return result, nil
+	return restack(repo.Stacks, repo.rootParent, branches)
 }
 
 // RestackCurrent restacks only the current stack.
@@ -34,11 +30,88 @@ func RestackCurrent(repo *Repo) (*RestackResult, error) {
 	if root == nil {
 		return nil, fmt.Errorf("current branch is not in a tracked stack")
 	}
+	return restack([]*Branch{root}, repo.rootParent, AllBranchesInStack(root))
+}
+
+// RestackDescendants restacks every branch above branch, leaving branch
+// itself and the rest of its stack alone.
+func RestackDescendants(branch *Branch) (*RestackResult, error) {
+	var descendants []*Branch
+	for _, child := range branch.Children {
+		descendants = append(descendants, AllBranchesInStack(child)...)
+	}
+	if len(descendants) == 0 {
+		return &RestackResult{}, nil
+	}
+	return restack(branch.Children, func(*Branch) string { return branch.Name }, descendants)
+}
+
+// RestackSubtree restacks branch onto its parent and everything above it.
+func RestackSubtree(branch *Branch) (*RestackResult, error) {
+	return restack([]*Branch{branch}, func(*Branch) string { return branch.Parent }, AllBranchesInStack(branch))
+}
+
+// rootParent returns what a stack root is restacked onto: its own parent,
+// whether that is a trunk or an untracked branch, or the primary trunk if
+// that branch no longer exists.
+func (r *Repo) rootParent(root *Branch) string {
+	if r.IsTrunk(root.Parent) || git.BranchExists(root.Parent) {
+		return root.Parent
+	}
+	return r.Trunk
+}
+
+// restack snapshots branches, then rebases each root onto onto(root) and
+// everything above the roots onto its own parent, depth-first. On a
+// conflict, every branch not yet restacked is saved for 'st continue'.
+func restack(roots []*Branch, onto func(root *Branch) string, branches []*Branch) (*RestackResult, error) {
+	oldTips, err := saveSnapshot(branches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot branch tips: %w", err)
+	}
+
+	type step struct {
+		name, parent string
+		frozen       bool
+	}
+	var steps []step
+	for _, root := range roots {
+		for _, b := range AllBranchesInStack(root) {
+			p := b.Parent
+			if b == root {
+				p = onto(root)
+			}
+			steps = append(steps, step{b.Name, p, b.Frozen})
+		}
+	}
 
 	result := &RestackResult{}
-	if err := restackBranch(root, repo.Trunk, result); err != nil {
-		return result, err
+	for i, s := range steps {
+		if s.frozen {
+			result.Frozen = append(result.Frozen, s.name)
+			continue
+		}
+		rebased, err := doRebase(s.name, s.parent, oldTips)
+		if err != nil {
+			remaining := make([]string, 0, len(steps)-i)
+			for _, r := range steps[i:] {
+				remaining = append(remaining, r.name)
+			}
+			if saveErr := git.SetRestackState(remaining); saveErr != nil {
+				return result, fmt.Errorf("rebase conflict on %s, and failed to save state: %w", s.name, saveErr)
+			}
+			result.Conflict = s.name
+			return result, nil
+		}
+
+		if rebased {
+			result.Rebased = append(result.Rebased, s.name)
+		} else {
+			result.Skipped = append(result.Skipped, s.name)
+		}
 	}
+
+	git.ClearRestackState()
 	return result, nil
 }
 
@@ -49,25 +122,23 @@ func RestackRemaining() (*RestackResult, error) {
 		return nil, fmt.Errorf("no restack in progress")
 	}
 
-	branches := strings.Split(remaining, ",")
+	oldTips, _, _ := git.GetRestackSnapshot()
 	result := &RestackResult{}
 
-	for i, branchName := range branches {
-		branchName = strings.TrimSpace(branchName)
-		if branchName == "" {
+	for i, branchName := range remaining {
+		parent, err := GetParent(branchName)
+		if err != nil {
 			continue
 		}
-
-		parent, err := git.GetStackParent(branchName)
-		if err != nil {
+		if IsFrozen(branchName) {
+			result.Frozen = append(result.Frozen, branchName)
 			continue
 		}
 
-		rebased, err := doRebase(branchName, parent)
+		rebased, err := doRebase(branchName, parent, oldTips)
 		if err != nil {
 			// Save remaining branches
-			remainingBranches := branches[i:]
-			if err := git.SetRestackState(strings.Join(remainingBranches, ",")); err != nil {
+			if err := git.SetRestackState(remaining[i:]); err != nil {
 				return result, fmt.Errorf("failed to save restack state: %w", err)
 			}
 			result.Conflict = branchName
@@ -86,66 +157,143 @@ func RestackRemaining() (*RestackResult, error) {
 	return result, nil
 }
 
-func restackBranch(branch *Branch, expectedParent string, result *RestackResult) error {
-	rebased, err := doRebase(branch.Name, expectedParent)
-	if err != nil {
-		// Save remaining branches for continue
-		remaining := collectRemaining(branch)
-		if saveErr := git.SetRestackState(strings.Join(remaining, ",")); saveErr != nil {
-			return fmt.Errorf("rebase conflict on %s, and failed to save state: %w", branch.Name, saveErr)
+// AbortRestack aborts an in-progress restack, resetting every branch it
+// touched (and HEAD) back to the tips and bases recorded before the restack
+// started. Returns the names of the branches that were reset.
+func AbortRestack() ([]string, error) {
+	tips, bases, err := git.GetRestackSnapshot()
+	if err != nil || len(tips) == 0 {
+		return nil, fmt.Errorf("no restack snapshot found to roll back to")
+	}
+	head, _ := git.GetRestackHead()
+
+	if git.IsRebaseInProgress() {
+		if err := git.RebaseAbort(); err != nil {
+			return nil, fmt.Errorf("failed to abort rebase: %w", err)
 		}
-		result.Conflict = branch.Name
-		return nil
 	}
 
-	if rebased {
-		result.Rebased = append(result.Rebased, branch.Name)
-	} else {
-		result.Skipped = append(result.Skipped, branch.Name)
+	current, _ := git.CurrentBranch()
+
+	names := make([]string, 0, len(tips))
+	for name := range tips {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	for _, child := range branch.Children {
-		if err := restackBranch(child, branch.Name, result); err != nil {
-			return err
+	var reset []string
+	for _, name := range names {
+		if base := bases[name]; base != GetBase(name) {
+			if base == "" {
+				_ = UnsetBase(name)
+			} else if err := SetBase(name, base); err != nil {
+				return reset, fmt.Errorf("failed to restore base of %s: %w", name, err)
+			}
+		}
+
+		sha := tips[name]
+		tip, err := git.BranchTip(name)
+		if err != nil || tip == sha {
+			continue
+		}
+		if name == current {
+			err = git.ResetHard(sha)
+		} else {
+			err = git.SetBranchTip(name, sha)
+		}
+		if err != nil {
+			return reset, fmt.Errorf("failed to reset %s: %w", name, err)
 		}
-		if result.Conflict != "" {
-			return nil
+		reset = append(reset, name)
+	}
+
+	if head != "" && head != current {
+		if err := git.Checkout(head); err != nil {
+			return reset, fmt.Errorf("failed to checkout %s: %w", head, err)
 		}
 	}
-	return nil
+
+	git.ClearRestackState()
+	return reset, nil
 }
 
-// doRebase rebases branch onto expectedParent if needed.
-// Returns true if a rebase was performed.
-func doRebase(branchName, expectedParent string) (bool, error) {
-	mb, err := git.MergeBase(branchName, expectedParent)
-	if err != nil {
-		return false, fmt.Errorf("could not find merge-base for %s and %s: %w", branchName, expectedParent, err)
+// saveSnapshot records the tips and bases of the given branches and the
+// current branch so an interrupted restack can be rolled back with
+// 'st abort'. Returns the recorded tips.
+func saveSnapshot(branches []*Branch) (map[string]string, error) {
+	head, _ := git.CurrentBranch()
+	tips := make(map[string]string)
+	bases := make(map[string]string)
+	for _, b := range branches {
+		tip, err := git.BranchTip(b.Name)
+		if err != nil {
+			continue
+		}
+		tips[b.Name] = tip
+		bases[b.Name] = b.Base
 	}
+	return tips, git.SetRestackSnapshot(head, tips, bases)
+}
 
-	parentTip, err := git.BranchTip(expectedParent)
+// doRebase rebases branch onto expectedParent if needed. oldTips holds the
+// branch tips from before the restack started.
+// Returns true if a rebase was performed.
+func doRebase(branchName, expectedParent string, oldTips map[string]string) (bool, error) {
+	base := GetBase(branchName)
+	upstream, parentTip, err := planRebase(liveHistory{}, branchName, expectedParent, base, oldTips)
 	if err != nil {
-		return false, fmt.Errorf("could not get tip of %s: %w", expectedParent, err)
+		return false, err
 	}
 
-	if mb == parentTip {
-		// Already up to date
+	if upstream == "" {
+		// Already up to date; make sure the base reflects that
+		if base != parentTip {
+			_ = SetBase(branchName, parentTip)
+		}
 		return false, nil
 	}
 
-	err = git.RebaseOnto(expectedParent, mb, branchName)
+	err = git.RebaseOnto(expectedParent, upstream, branchName)
 	if err != nil {
 		return false, err
 	}
+	_ = SetBase(branchName, parentTip)
 	return true, nil
 }
 
-// collectRemaining collects remaining branch names from the current branch onward (DFS).
-func collectRemaining(branch *Branch) []string {
-	var result []string
-	result = append(result, branch.Name)
-	for _, child := range branch.Children {
-		result = append(result, collectRemaining(child)...)
+// planRebase decides whether branch needs rebasing onto parent. If it does,
+// upstream is the commit after which the branch's own commits start; if it
+// is up to date, upstream is empty.
+func planRebase(h history, branchName, parent, base string, oldTips map[string]string) (upstream, parentTip string, err error) {
+	mb, err := h.MergeBase(branchName, parent)
+	if err != nil {
+		return "", "", fmt.Errorf("could not find merge-base for %s and %s: %w", branchName, parent, err)
+	}
+
+	parentTip, err = h.Tip(parent)
+	if err != nil {
+		return "", "", fmt.Errorf("could not get tip of %s: %w", parent, err)
+	}
+
+	if mb == parentTip {
+		// The parent is in the branch's history, but if the branch was built
+		// on commits above the parent's tip (it was moved onto an ancestor,
+		// or the parent was reset), those no longer belong to it.
+		if base != "" && base != parentTip && h.IsAncestor(parentTip, base) && h.IsAncestor(base, branchName) {
+			return base, parentTip, nil
+		}
+		return "", parentTip, nil
+	}
+
+	// Replay only the branch's own commits: those after its recorded base,
+	// or failing that after the parent's tip from before this restack
+	// rewrote it. The merge-base alone would replay the old parent's
+	// commits too, once the parent has been amended or squash-merged.
+	if base != "" && h.IsAncestor(base, branchName) {
+		return base, parentTip, nil
+	}
+	if old, ok := oldTips[parent]; ok && old != parentTip && h.IsAncestor(old, branchName) {
+		return old, parentTip, nil
 	}
-	return result
+	return mb, parentTip, nil
 }
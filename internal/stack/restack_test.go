@@ -0,0 +1,219 @@
+package stack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+func TestRestackAll_RootOnFormerTrunk(t *testing.T) {
+	r := newTestRepo(t)
+	r.git("branch", "release/1.x")
+	if err := git.AddTrunk("release/1.x"); err != nil {
+		t.Fatal(err)
+	}
+	r.branch("fix", "release/1.x", "fix bug")
+	r.branch("feat", "main", "add feature")
+	if err := git.RemoveTrunk("release/1.x"); err != nil {
+		t.Fatal(err)
+	}
+
+	r.git("checkout", "-q", "release/1.x")
+	r.commit("backport")
+	r.git("checkout", "-q", "main")
+	r.commit("main work")
+
+	result, err := RestackAll(r.load())
+	if err != nil || result.Conflict != "" {
+		t.Fatalf("RestackAll: %v, conflict %q", err, result.Conflict)
+	}
+	if got := r.subjects("main", "fix"); !reflect.DeepEqual(got, []string{"backport", "fix bug"}) {
+		t.Errorf("fix should stay on release/1.x, got main..fix = %q", got)
+	}
+	if got := r.subjects("main", "feat"); !reflect.DeepEqual(got, []string{"add feature"}) {
+		t.Errorf("feat should be restacked onto main, got main..feat = %q", got)
+	}
+}
+
+func TestPlanRebase(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a", "b1")
+	oldA := r.tip("a")
+	mainTip := r.tip("main")
+
+	check := func(desc, branch, parent, base string, oldTips map[string]string, want string) {
+		t.Helper()
+		upstream, parentTip, err := planRebase(liveHistory{}, branch, parent, base, oldTips)
+		if err != nil {
+			t.Fatalf("%s: %v", desc, err)
+		}
+		if upstream != want {
+			t.Errorf("%s: upstream = %q, want %q", desc, upstream, want)
+		}
+		if tip := r.tip(parent); parentTip != tip {
+			t.Errorf("%s: parent tip = %s, want %s", desc, parentTip, tip)
+		}
+	}
+
+	check("up to date", "b", "a", oldA, nil, "")
+	check("moved onto an ancestor", "b", "main", oldA, nil, oldA)
+	check("moved onto an ancestor, no base", "b", "main", "", nil, "")
+
+	r.git("checkout", "-q", "a")
+	r.git("commit", "-q", "--amend", "-m", "a1 amended")
+	check("parent amended, base recorded", "b", "a", oldA, nil, oldA)
+	check("parent amended, old tip known", "b", "a", "", map[string]string{"a": oldA}, oldA)
+	check("parent amended, nothing known", "b", "a", "", nil, mainTip)
+}
+
+func TestRestackAll_ParentAmended(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a", "b1")
+	r.branch("c", "b", "c1")
+	if err := EnsureBase("b", "a"); err != nil {
+		t.Fatal(err)
+	}
+	r.git("checkout", "-q", "a")
+	r.git("commit", "-q", "--amend", "-m", "a1 amended")
+
+	result, err := RestackAll(r.load())
+	if err != nil || result.Conflict != "" {
+		t.Fatalf("RestackAll: %v, conflict %q", err, result.Conflict)
+	}
+	if !reflect.DeepEqual(result.Rebased, []string{"b", "c"}) {
+		t.Errorf("rebased %q, want [b c]", result.Rebased)
+	}
+	if got := r.subjects("main", "c"); !reflect.DeepEqual(got, []string{"a1 amended", "b1", "c1"}) {
+		t.Errorf("main..c = %q, want the old a1 dropped", got)
+	}
+	for branch, parent := range map[string]string{"a": "main", "b": "a", "c": "b"} {
+		if got, want := GetBase(branch), r.tip(parent); got != want {
+			t.Errorf("base of %s = %s, want the tip of %s (%s)", branch, got, parent, want)
+		}
+	}
+}
+
+func TestAbortRestack(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main")
+	r.commitFile("f.txt", "one\n", "a1")
+	r.branch("b", "a")
+	r.commitFile("f.txt", "two\n", "b1")
+	r.branch("c", "b", "c1")
+	if err := EnsureBase("b", "a"); err != nil {
+		t.Fatal(err)
+	}
+	r.git("checkout", "-q", "a")
+	r.commitFile("f.txt", "three\n", "a2")
+
+	tips := map[string]string{"a": r.tip("a"), "b": r.tip("b"), "c": r.tip("c")}
+	baseB := GetBase("b")
+
+	result, err := RestackAll(r.load())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Conflict != "b" {
+		t.Fatalf("expected a conflict on b, got %+v", result)
+	}
+	if GetBase("a") == "" {
+		t.Fatal("restacking a should have recorded its base")
+	}
+
+	reset, err := AbortRestack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reset) != 0 {
+		t.Errorf("reset %q, but no branch had moved yet", reset)
+	}
+	if git.IsRebaseInProgress() || git.IsRestackInProgress() {
+		t.Error("abort should clear the rebase and the restack state")
+	}
+	for name, want := range tips {
+		if got := r.tip(name); got != want {
+			t.Errorf("%s at %s, want %s", name, got, want)
+		}
+	}
+	if got := GetBase("a"); got != "" {
+		t.Errorf("base of a = %s, want none as before", got)
+	}
+	if got := GetBase("b"); got != baseB {
+		t.Errorf("base of b = %s, want %s", got, baseB)
+	}
+	if head := r.head(); head != "a" {
+		t.Errorf("checked out %q, want a", head)
+	}
+}
+
+func TestAbortRestack_AfterPartialRestack(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a")
+	r.commitFile("f.txt", "one\n", "b1")
+	r.branch("c", "b")
+	r.commitFile("f.txt", "two\n", "c1")
+	r.git("checkout", "-q", "b")
+	r.commitFile("f.txt", "three\n", "b2")
+	r.git("checkout", "-q", "a")
+	r.commit("a2")
+
+	tips := map[string]string{"a": r.tip("a"), "b": r.tip("b"), "c": r.tip("c")}
+
+	result, err := RestackAll(r.load())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Conflict != "c" || !reflect.DeepEqual(result.Rebased, []string{"b"}) {
+		t.Fatalf("expected b rebased and a conflict on c, got %+v", result)
+	}
+
+	reset, err := AbortRestack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reset, []string{"b"}) {
+		t.Errorf("reset %q, want [b]", reset)
+	}
+	for name, want := range tips {
+		if got := r.tip(name); got != want {
+			t.Errorf("%s at %s, want %s", name, got, want)
+		}
+	}
+	if head := r.head(); head != "a" {
+		t.Errorf("checked out %q, want a", head)
+	}
+}
+
+func TestRestackDescendants(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a", "b1")
+	r.branch("c", "b", "c1")
+	r.branch("other", "a", "other1")
+	r.git("checkout", "-q", "main")
+	r.commit("main work")
+	r.git("checkout", "-q", "b")
+	r.commit("b2")
+	tipA, tipOther := r.tip("a"), r.tip("other")
+
+	result, err := RestackDescendants(r.load().Branches["b"])
+	if err != nil || result.Conflict != "" {
+		t.Fatalf("RestackDescendants: %v, conflict %q", err, result.Conflict)
+	}
+	if !reflect.DeepEqual(result.Rebased, []string{"c"}) {
+		t.Errorf("rebased %q, want [c]", result.Rebased)
+	}
+	if got := r.subjects("b", "c"); !reflect.DeepEqual(got, []string{"c1"}) {
+		t.Errorf("b..c = %q, want [c1]", got)
+	}
+	if got := GetBase("c"); got != r.tip("b") {
+		t.Errorf("base of c = %s, want the tip of b", got)
+	}
+	if r.tip("a") != tipA || r.tip("other") != tipOther {
+		t.Error("branches outside b's subtree should be left alone")
+	}
+}
@@ -0,0 +1,118 @@
+package stack
+
+import "github.com/rodrigolobo/st/internal/git"
+
+// history answers the questions about commits that planning a rebase needs.
+type history interface {
+	Tip(branch string) (string, error)
+	MergeBase(a, b string) (string, error)
+	IsAncestor(ancestor, descendant string) bool
+}
+
+// liveHistory asks git every time, for code that moves branches as it goes.
+type liveHistory struct{}
+
+func (liveHistory) Tip(branch string) (string, error)     { return git.BranchTip(branch) }
+func (liveHistory) MergeBase(a, b string) (string, error) { return git.MergeBase(a, b) }
+func (liveHistory) IsAncestor(a, d string) bool           { return git.IsAncestor(a, d) }
+
+// Snapshot caches branch tips and the commit graph around the tracked
+// branches, so that rendering a large tree takes a handful of git calls
+// instead of several per branch. It reflects the repository when it was
+// loaded and must not be used once branches have moved; get one with
+// Repo.Snapshot. Questions it can't answer from the cache go to git.
+type Snapshot struct {
+	tips  map[string]string // branch -> tip SHA
+	graph *git.Graph        // nil if it could not be loaded
+}
+
+// Snapshot returns the repo's snapshot, loading it on first use.
+func (r *Repo) Snapshot() *Snapshot {
+	if r.snapshot == nil {
+		r.snapshot = LoadSnapshot(r)
+	}
+	return r.snapshot
+}
+
+// LoadSnapshot reads every branch tip with one for-each-ref and the commit
+// graph above the merge-base of the trunks, the tracked branches and their
+// parents with one rev-list.
+func LoadSnapshot(repo *Repo) *Snapshot {
+	s := &Snapshot{}
+	tips, err := git.BranchTips()
+	if err != nil {
+		return s
+	}
+	s.tips = tips
+
+	seen := make(map[string]bool)
+	var shas []string
+	add := func(name string) {
+		if sha, ok := tips[name]; ok && !seen[sha] {
+			seen[sha] = true
+			shas = append(shas, sha)
+		}
+	}
+	for _, t := range repo.Trunks {
+		add(t)
+	}
+	for _, b := range repo.Branches {
+		add(b.Name)
+		add(b.Parent)
+	}
+	if graph, err := git.LoadGraph(shas); err == nil {
+		s.graph = graph
+	}
+	return s
+}
+
+// resolve returns the SHA a branch name or full SHA refers to, or "" if it
+// is neither a known branch nor a full SHA.
+func (s *Snapshot) resolve(ref string) string {
+	if sha, ok := s.tips[ref]; ok {
+		return sha
+	}
+	if len(ref) == 40 {
+		return ref
+	}
+	return ""
+}
+
+// Tip returns the SHA a branch points to.
+func (s *Snapshot) Tip(branch string) (string, error) {
+	if sha, ok := s.tips[branch]; ok {
+		return sha, nil
+	}
+	return git.BranchTip(branch)
+}
+
+// MergeBase returns the best common ancestor of two branches or commits.
+func (s *Snapshot) MergeBase(a, b string) (string, error) {
+	if s.graph != nil {
+		if mb, ok := s.graph.MergeBase(s.resolve(a), s.resolve(b)); ok {
+			return mb, nil
+		}
+	}
+	return git.MergeBase(a, b)
+}
+
+// IsAncestor reports whether ancestor is in the history of descendant.
+func (s *Snapshot) IsAncestor(ancestor, descendant string) bool {
+	if s.graph != nil {
+		if result, ok := s.graph.IsAncestor(s.resolve(ancestor), s.resolve(descendant)); ok {
+			return result
+		}
+	}
+	return git.IsAncestor(ancestor, descendant)
+}
+
+// CommitCount returns the number of commits in descendant that are not in
+// ancestor.
+func (s *Snapshot) CommitCount(ancestor, descendant string) (int, error) {
+	if s.graph != nil {
+		if n, ok := s.graph.Count(s.resolve(ancestor), s.resolve(descendant)); ok {
+			return n, nil
+		}
+	}
+	return git.CommitCount(ancestor, descendant)
+}
@@ -0,0 +1,216 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// SplitPieceName returns the name of the nth (1-based) branch split off name.
+func SplitPieceName(name string, n int) string {
+	return fmt.Sprintf("%s-part%d", name, n)
+}
+
+// SplitBranch splits branch at the given commits, each of which becomes the
+// tip of a new tracked branch. The new branches form a chain from the
+// branch's parent up to the branch itself, which keeps its name, its last
+// commits and its children. No commits are rewritten. Returns the names of
+// the new branches, bottom first.
+func SplitBranch(branch *Branch, points []string) ([]string, error) {
+	commits, err := git.CommitsBetween(branch.Parent, branch.Name)
+	if err != nil {
+		return nil, fmt.Errorf("could not list commits of %s: %w", branch.Name, err)
+	}
+
+	resolved := make([]string, 0, len(points))
+	for _, p := range points {
+		sha, err := git.RevParse(p + "^{commit}")
+		if err != nil {
+			return nil, fmt.Errorf("unknown commit %q", p)
+		}
+		resolved = append(resolved, sha)
+	}
+	ordered, err := orderSplitPoints(commits, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for i := range ordered {
+		name := SplitPieceName(branch.Name, i+1)
+		if git.BranchExists(name) {
+			return nil, fmt.Errorf("branch %q already exists", name)
+		}
+		names = append(names, name)
+	}
+
+	if err := EnsureBase(branch.Name, branch.Parent); err != nil {
+		return nil, fmt.Errorf("could not find where %s starts: %w", branch.Name, err)
+	}
+	parent, base := branch.Parent, GetBase(branch.Name)
+	for i, name := range names {
+		if err := git.CreateBranchAt(name, ordered[i]); err != nil {
+			return names[:i], fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		if err := TrackBranch(name, parent); err != nil {
+			return names[:i+1], fmt.Errorf("failed to track %s: %w", name, err)
+		}
+		if err := SetBase(name, base); err != nil {
+			return names[:i+1], fmt.Errorf("failed to record base of %s: %w", name, err)
+		}
+		parent, base = name, ordered[i]
+	}
+
+	if err := ReparentBranch(branch.Name, parent); err != nil {
+		return names, fmt.Errorf("failed to reparent %s: %w", branch.Name, err)
+	}
+	if err := SetBase(branch.Name, base); err != nil {
+		return names, fmt.Errorf("failed to record base of %s: %w", branch.Name, err)
+	}
+	return names, nil
+}
+
+// orderSplitPoints checks that every point is one of commits other than the
+// last, and returns them deduplicated in commit order.
+func orderSplitPoints(commits, points []string) ([]string, error) {
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no split points given")
+	}
+	position := make(map[string]int, len(commits))
+	for i, sha := range commits {
+		position[sha] = i
+	}
+
+	selected := make(map[string]bool)
+	for _, p := range points {
+		i, ok := position[p]
+		if !ok {
+			return nil, fmt.Errorf("commit %s is not on the branch", short(p))
+		}
+		if i == len(commits)-1 {
+			return nil, fmt.Errorf("commit %s is the branch tip; it cannot end a new branch", short(p))
+		}
+		selected[p] = true
+	}
+
+	var ordered []string
+	for _, sha := range commits {
+		if selected[sha] {
+			ordered = append(ordered, sha)
+		}
+	}
+	return ordered, nil
+}
+
+// SplitBranchByHunk rewrites branch as a chain of commits, one per piece,
+// where assign[file][unit] is the 1-based piece each hunk of files (the
+// diff of the branch against its parent) goes to. Every piece but the last
+// becomes a new tracked branch; the last is branch itself, whose final tree
+// is unchanged, so the combined diff of the chain equals the original.
+// Branch's children are left on its old tip for the caller to restack.
+// Returns the names of the new branches, bottom first.
+func SplitBranchByHunk(branch *Branch, files []git.FileDiff, assign [][]int) ([]string, error) {
+	pieces := 0
+	used := make(map[int]bool)
+	for _, units := range assign {
+		for _, p := range units {
+			pieces = max(pieces, p)
+			used[p] = true
+		}
+	}
+	if pieces < 2 {
+		return nil, fmt.Errorf("assign hunks to at least two branches to split")
+	}
+	for p := 1; p <= pieces; p++ {
+		if !used[p] {
+			return nil, fmt.Errorf("no hunks assigned to branch %d", p)
+		}
+	}
+
+	var names []string
+	for i := 1; i < pieces; i++ {
+		name := SplitPieceName(branch.Name, i)
+		if git.BranchExists(name) {
+			return nil, fmt.Errorf("branch %q already exists", name)
+		}
+		names = append(names, name)
+	}
+
+	parentTip, err := git.BranchTip(branch.Parent)
+	if err != nil {
+		return nil, fmt.Errorf("could not get tip of %s: %w", branch.Parent, err)
+	}
+	subject, _, err := git.FirstCommitMessage(branch.Parent, branch.Name)
+	if err != nil {
+		return nil, err
+	}
+	message, err := git.CommitMessages(branch.Parent, branch.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the chain of commits before touching any branch
+	commits := make([]string, 0, pieces)
+	prev := parentTip
+	for i := 1; i <= pieces; i++ {
+		var tree string
+		if i == pieces {
+			tree, err = git.RevParse(branch.Name + "^{tree}")
+		} else {
+			patch := git.BuildPatch(files, func(file, unit int) bool {
+				return assign[file][unit] <= i
+			})
+			tree, err = git.ApplyToTree(parentTip, patch)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to build branch %d of %d: %w", i, pieces, err)
+		}
+
+		msg := message
+		if i < pieces {
+			msg = fmt.Sprintf("%s (%d/%d)", subject, i, pieces)
+		}
+		commit, err := git.CommitTree(tree, prev, msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to commit branch %d of %d: %w", i, pieces, err)
+		}
+		commits = append(commits, commit)
+		prev = commit
+	}
+
+	// Children were built on the branch's current tip
+	for _, child := range branch.Children {
+		_ = EnsureBase(child.Name, branch.Name)
+	}
+
+	parent, base := branch.Parent, parentTip
+	for i, name := range names {
+		if err := git.CreateBranchAt(name, commits[i]); err != nil {
+			return names[:i], fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		if err := TrackBranch(name, parent); err != nil {
+			return names[:i+1], fmt.Errorf("failed to track %s: %w", name, err)
+		}
+		if err := SetBase(name, base); err != nil {
+			return names[:i+1], fmt.Errorf("failed to record base of %s: %w", name, err)
+		}
+		parent, base = name, commits[i]
+	}
+
+	tip := commits[len(commits)-1]
+	if current, _ := git.CurrentBranch(); current == branch.Name {
+		err = git.ResetHard(tip)
+	} else {
+		err = git.SetBranchTip(branch.Name, tip)
+	}
+	if err != nil {
+		return names, fmt.Errorf("failed to move %s: %w", branch.Name, err)
+	}
+	if err := ReparentBranch(branch.Name, parent); err != nil {
+		return names, fmt.Errorf("failed to reparent %s: %w", branch.Name, err)
+	}
+	if err := SetBase(branch.Name, base); err != nil {
+		return names, fmt.Errorf("failed to record base of %s: %w", branch.Name, err)
+	}
+	return names, nil
+}
@@ -0,0 +1,180 @@
+package stack
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+func TestOrderSplitPoints_SortsAndDedupes(t *testing.T) {
+	commits := []string{"c1", "c2", "c3", "c4"}
+
+	got, err := orderSplitPoints(commits, []string{"c3", "c1", "c3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"c1", "c3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestOrderSplitPoints_Errors(t *testing.T) {
+	commits := []string{"c1", "c2", "c3"}
+	tests := []struct {
+		name   string
+		points []string
+		want   string
+	}{
+		{"none", nil, "no split points"},
+		{"not on branch", []string{"zz"}, "not on the branch"},
+		{"tip", []string{"c3"}, "branch tip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := orderSplitPoints(commits, tt.points)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSplitPieceName(t *testing.T) {
+	if got := SplitPieceName("feat", 2); got != "feat-part2" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSplitBranch(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a", "b1", "b2", "b3", "b4")
+	r.branch("c", "b", "c1")
+	tipA, tipB, tipC := r.tip("a"), r.tip("b"), r.tip("c")
+	b1, b3 := r.tip("b~3"), r.tip("b~1")
+
+	var names []string
+	r.record("split", func() error {
+		var err error
+		names, err = SplitBranch(r.load().Branches["b"], []string{b3, "b~3"})
+		return err
+	})
+
+	if !reflect.DeepEqual(names, []string{"b-part1", "b-part2"}) {
+		t.Fatalf("created %q, want [b-part1 b-part2]", names)
+	}
+	for name, want := range map[string]string{"b-part1": b1, "b-part2": b3, "b": tipB, "c": tipC} {
+		if got := r.tip(name); got != want {
+			t.Errorf("%s at %s, want %s", name, got, want)
+		}
+	}
+	for name, want := range map[string]struct{ parent, base string }{
+		"b-part1": {"a", tipA},
+		"b-part2": {"b-part1", b1},
+		"b":       {"b-part2", b3},
+	} {
+		if parent := r.parent(name); parent != want.parent {
+			t.Errorf("parent of %s = %q, want %q", name, parent, want.parent)
+		}
+		if base := GetBase(name); base != want.base {
+			t.Errorf("base of %s = %s, want %s", name, base, want.base)
+		}
+	}
+	if parent := r.parent("c"); parent != "b" {
+		t.Errorf("parent of c = %q, want b", parent)
+	}
+	if result, err := RestackAll(r.load()); err != nil || len(result.Rebased) != 0 {
+		t.Errorf("nothing should need a restack after a split, got %+v, %v", result, err)
+	}
+
+	r.undo(1)
+	for _, name := range names {
+		if git.BranchExists(name) {
+			t.Errorf("%s should be gone after undo", name)
+		}
+	}
+	if parent := r.parent("b"); parent != "a" || r.tip("b") != tipB {
+		t.Errorf("b on %q at %s after undo, want a at %s", parent, r.tip("b"), tipB)
+	}
+}
+
+func TestSplitBranchByHunk(t *testing.T) {
+	r := newTestRepo(t)
+	r.branch("a", "main", "a1")
+	r.branch("b", "a")
+	r.commitFile("x.txt", "x\n", "add x")
+	r.commitFile("y.txt", "y\n", "add y")
+	r.branch("c", "b", "c1")
+	r.git("checkout", "-q", "b")
+	tipA, tipB, tipC := r.tip("a"), r.tip("b"), r.tip("c")
+	treeB := r.tip("b^{tree}")
+
+	diff, err := git.Diff("a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := git.ParseDiff(diff)
+	if len(files) != 2 || files[0].Path != "x.txt" {
+		t.Fatalf("unexpected diff: %+v", files)
+	}
+
+	var names []string
+	r.record("split --by-hunk", func() error {
+		names, err = SplitBranchByHunk(r.load().Branches["b"], files, [][]int{{1}, {2}})
+		return err
+	})
+
+	if !reflect.DeepEqual(names, []string{"b-part1"}) {
+		t.Fatalf("created %q, want [b-part1]", names)
+	}
+	if got := r.git("ls-tree", "--name-only", "b-part1"); strings.Contains(got, "y.txt") || !strings.Contains(got, "x.txt") {
+		t.Errorf("b-part1 has %q, want x.txt without y.txt", got)
+	}
+	if got := r.tip("b^{tree}"); got != treeB {
+		t.Error("b's final tree should be unchanged")
+	}
+	if got := r.subjects("a", "b"); !reflect.DeepEqual(got, []string{"add x (1/2)", "add x"}) {
+		t.Errorf("a..b = %q, want one commit per piece", got)
+	}
+	for name, want := range map[string]struct{ parent, base string }{
+		"b-part1": {"a", tipA},
+		"b":       {"b-part1", r.tip("b-part1")},
+		"c":       {"b", tipB},
+	} {
+		if parent := r.parent(name); parent != want.parent {
+			t.Errorf("parent of %s = %q, want %q", name, parent, want.parent)
+		}
+		if base := GetBase(name); base != want.base {
+			t.Errorf("base of %s = %s, want %s", name, base, want.base)
+		}
+	}
+	if got := r.tip("c"); got != tipC {
+		t.Errorf("c moved to %s; it should be left for the caller to restack", got)
+	}
+
+	r.record("restack", func() error {
+		result, err := RestackDescendants(r.load().Branches["b"])
+		if err == nil && result.Conflict != "" {
+			t.Fatalf("conflict on %s", result.Conflict)
+		}
+		return err
+	})
+	if got := r.subjects("b", "c"); !reflect.DeepEqual(got, []string{"c1"}) {
+		t.Errorf("b..c = %q, want [c1]", got)
+	}
+
+	r.undo(2)
+	if git.BranchExists("b-part1") {
+		t.Error("b-part1 should be gone after undo")
+	}
+	for name, want := range map[string]string{"b": tipB, "c": tipC} {
+		if got := r.tip(name); got != want {
+			t.Errorf("%s at %s after undo, want %s", name, got, want)
+		}
+	}
+	if parent := r.parent("b"); parent != "a" {
+		t.Errorf("parent of b = %q after undo, want a", parent)
+	}
+}
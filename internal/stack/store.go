@@ -0,0 +1,337 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// Meta is the stack metadata recorded for one branch.
+type Meta struct {
+	Parent string `json:"parent"`
+	Base   string `json:"base,omitempty"`
+	Frozen bool   `json:"frozen,omitempty"`
+}
+
+// MetadataStore persists the stack metadata of tracked branches.
+type MetadataStore interface {
+	// Name identifies the backend, as used in the st.metadata setting.
+	Name() string
+	// Load returns the metadata of every tracked branch.
+	Load() (map[string]Meta, error)
+	// Read returns a branch's metadata and whether it is tracked.
+	Read(branch string) (Meta, bool, error)
+	// Write replaces a branch's metadata, tracking it if needed.
+	Write(branch string, meta Meta) error
+	// Delete untracks a branch.
+	Delete(branch string) error
+}
+
+// Metadata backends.
+const (
+	StoreConfig = "config" // stack.<branch>.* keys in .git/config
+	StoreRefs   = "refs"   // one JSON blob per branch under refs/st/meta/
+)
+
+// MetaRefPrefix is where the refs backend keeps its blobs.
+const MetaRefPrefix = "refs/st/meta/"
+
+// OpenStore returns the metadata backend with the given name.
+func OpenStore(name string) (MetadataStore, error) {
+	switch name {
+	case StoreConfig:
+		return configStore{}, nil
+	case StoreRefs:
+		return refsStore{prefix: MetaRefPrefix}, nil
+	}
+	return nil, fmt.Errorf("unknown metadata store %q (want %s or %s)", name, StoreConfig, StoreRefs)
+}
+
+// selectedStore caches the backend selected by st.metadata. Each st command
+// runs in its own process, so the setting is read once per command rather
+// than on every metadata access.
+var selectedStore struct {
+	resolved bool
+	store    MetadataStore
+	err      error
+}
+
+// CurrentStore returns the backend selected by st.metadata, defaulting to
+// git config. An unknown value is an error rather than a silent fallback,
+// which would hide the branches kept in the intended store.
+func CurrentStore() (MetadataStore, error) {
+	if !selectedStore.resolved {
+		selectedStore.store, selectedStore.err = openCurrentStore()
+		selectedStore.resolved = true
+	}
+	return selectedStore.store, selectedStore.err
+}
+
+func openCurrentStore() (MetadataStore, error) {
+	name, err := git.ConfigGet("st.metadata")
+	if err != nil || name == "" {
+		return configStore{}, nil
+	}
+	return OpenStore(name)
+}
+
+// RemoteStore returns the metadata last fetched from a remote with
+// 'st metadata fetch'. It is read-only in practice.
+func RemoteStore(remote string) MetadataStore {
+	return refsStore{prefix: remoteMetaPrefix(remote)}
+}
+
+func remoteMetaPrefix(remote string) string {
+	return "refs/st/remotes/" + remote + "/meta/"
+}
+
+// SetCurrentStore selects the backend used from now on. It does not move
+// any metadata; see MigrateStore.
+func SetCurrentStore(name string) error {
+	store, err := OpenStore(name)
+	if err != nil {
+		return err
+	}
+	if err := git.ConfigSet("st.metadata", name); err != nil {
+		return err
+	}
+	selectedStore.store, selectedStore.err, selectedStore.resolved = store, nil, true
+	return nil
+}
+
+// MigrateStore copies every branch's metadata from one backend to another,
+// removes it from the source, and selects the target. Returns the number of
+// branches moved.
+func MigrateStore(from, to MetadataStore) (int, error) {
+	metas, err := from.Load()
+	if err != nil {
+		return 0, err
+	}
+	for name, meta := range metas {
+		if err := to.Write(name, meta); err != nil {
+			return 0, fmt.Errorf("failed to write metadata of %s: %w", name, err)
+		}
+	}
+	if err := SetCurrentStore(to.Name()); err != nil {
+		return 0, err
+	}
+	for name := range metas {
+		if err := from.Delete(name); err != nil {
+			return len(metas), fmt.Errorf("failed to remove old metadata of %s: %w", name, err)
+		}
+	}
+	return len(metas), nil
+}
+
+// PushMetadata publishes the refs backend's metadata to a remote.
+func PushMetadata(remote string) error {
+	store, err := CurrentStore()
+	if err != nil {
+		return err
+	}
+	if store.Name() != StoreRefs {
+		return fmt.Errorf("metadata is kept in git config, which can't be pushed. Run 'st metadata migrate --to refs' first")
+	}
+	return git.PushRefs(remote, MetaRefPrefix+"*")
+}
+
+// FetchMetadata fetches the metadata published on a remote, then tracks
+// every local branch that the remote knows about but this clone doesn't.
+// Returns the names of the branches it started tracking.
+func FetchMetadata(remote string) ([]string, error) {
+	theirs, err := FetchRemoteMetadata(remote)
+	if err != nil {
+		return nil, err
+	}
+	store, err := CurrentStore()
+	if err != nil {
+		return nil, err
+	}
+	ours, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var imported []string
+	for name, meta := range theirs {
+		if _, tracked := ours[name]; tracked || !git.BranchExists(name) {
+			continue
+		}
+		if err := store.Write(name, meta); err != nil {
+			return imported, fmt.Errorf("failed to track %s: %w", name, err)
+		}
+		imported = append(imported, name)
+	}
+	return imported, nil
+}
+
+// configStore keeps metadata in stack.<branch>.parent, stack.<branch>.base
+// and stack.<branch>.frozen keys in .git/config.
+type configStore struct{}
+
+func (configStore) Name() string { return StoreConfig }
+
+func (configStore) Load() (map[string]Meta, error) {
+	entries, err := git.ConfigGetRegexp(`^stack\.`)
+	if err != nil {
+		return nil, fmt.Errorf("could not read stack config: %w", err)
+	}
+
+	metas := make(map[string]Meta)
+	for _, entry := range entries {
+		name, field, ok := parseStackKey(entry[0])
+		if !ok {
+			continue
+		}
+		meta := metas[name]
+		switch field {
+		case "parent":
+			meta.Parent = entry[1]
+		case "base":
+			meta.Base = entry[1]
+		case "frozen":
+			meta.Frozen = entry[1] == "true"
+		default:
+			continue
+		}
+		metas[name] = meta
+	}
+
+	// A base without a parent is left over, not tracked
+	for name, meta := range metas {
+		if meta.Parent == "" {
+			delete(metas, name)
+		}
+	}
+	return metas, nil
+}
+
+// parseStackKey splits a key like "stack.release.2.x-fix.parent" into the
+// branch name and the field. Git keeps the subsection between the first and
+// the last dot verbatim, so the branch name may itself contain dots.
+func parseStackKey(key string) (branch, field string, ok bool) {
+	rest, ok := strings.CutPrefix(key, "stack.")
+	if !ok {
+		return "", "", false
+	}
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 {
+		return "", "", false
+	}
+	return rest[:i], rest[i+1:], true
+}
+
+func (configStore) Read(branch string) (Meta, bool, error) {
+	parent, err := git.GetStackParent(branch)
+	if err != nil {
+		return Meta{}, false, nil
+	}
+	base, _ := git.GetStackBase(branch)
+	frozen, _ := git.ConfigGet(fmt.Sprintf("stack.%s.frozen", branch))
+	return Meta{Parent: parent, Base: base, Frozen: frozen == "true"}, true, nil
+}
+
+func (configStore) Write(branch string, meta Meta) error {
+	if err := git.SetStackParent(branch, meta.Parent); err != nil {
+		return err
+	}
+	if meta.Frozen {
+		if err := git.SetStackFrozen(branch, true); err != nil {
+			return err
+		}
+	} else {
+		_ = git.SetStackFrozen(branch, false)
+	}
+	if meta.Base == "" {
+		_ = git.UnsetStackBase(branch)
+		return nil
+	}
+	return git.SetStackBase(branch, meta.Base)
+}
+
+func (configStore) Delete(branch string) error {
+	return git.RemoveStackSection(branch)
+}
+
+// refsStore keeps each branch's metadata as a JSON blob at <prefix><branch>.
+type refsStore struct {
+	prefix string
+}
+
+func (refsStore) Name() string { return StoreRefs }
+
+func (s refsStore) Load() (map[string]Meta, error) {
+	refs, err := git.ListRefs(s.prefix)
+	if err != nil {
+		return nil, fmt.Errorf("could not list metadata refs: %w", err)
+	}
+	metas := make(map[string]Meta, len(refs))
+	for name, sha := range refs {
+		meta, err := readMetaBlob(sha)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt metadata for %s: %w", name, err)
+		}
+		metas[name] = meta
+	}
+	return metas, nil
+}
+
+func (s refsStore) Read(branch string) (Meta, bool, error) {
+	sha, err := git.RevParse(s.prefix + branch)
+	if err != nil {
+		return Meta{}, false, nil
+	}
+	meta, err := readMetaBlob(sha)
+	if err != nil {
+		return Meta{}, false, fmt.Errorf("corrupt metadata for %s: %w", branch, err)
+	}
+	return meta, true, nil
+}
+
+func (s refsStore) Write(branch string, meta Meta) error {
+	data, err := encodeMeta(meta)
+	if err != nil {
+		return err
+	}
+	sha, err := git.HashObject(data)
+	if err != nil {
+		return err
+	}
+	return git.UpdateRef(s.prefix+branch, sha)
+}
+
+func (s refsStore) Delete(branch string) error {
+	if _, err := git.RevParse(s.prefix + branch); err != nil {
+		return nil // not tracked
+	}
+	return git.DeleteRef(s.prefix + branch)
+}
+
+func readMetaBlob(sha string) (Meta, error) {
+	data, err := git.ReadBlob(sha)
+	if err != nil {
+		return Meta{}, err
+	}
+	return decodeMeta(data)
+}
+
+func encodeMeta(meta Meta) (string, error) {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func decodeMeta(data string) (Meta, error) {
+	var meta Meta
+	if err := json.Unmarshal([]byte(data), &meta); err != nil {
+		return Meta{}, err
+	}
+	if meta.Parent == "" {
+		return Meta{}, fmt.Errorf("no parent recorded")
+	}
+	return meta, nil
+}
@@ -0,0 +1,123 @@
+package stack
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMetaRoundTrip(t *testing.T) {
+	for _, meta := range []Meta{
+		{Parent: "main"},
+		{Parent: "feat/auth", Base: "0123456789abcdef0123456789abcdef01234567"},
+		{Parent: "main", Frozen: true},
+	} {
+		data, err := encodeMeta(meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodeMeta(data)
+		if err != nil {
+			t.Fatalf("decodeMeta(%q): %v", data, err)
+		}
+		if got != meta {
+			t.Errorf("round trip of %+v gave %+v", meta, got)
+		}
+	}
+}
+
+func TestDecodeMeta_Invalid(t *testing.T) {
+	for _, data := range []string{"", "not json", `{"base":"abc"}`} {
+		if _, err := decodeMeta(data); err == nil {
+			t.Errorf("decodeMeta(%q) should fail", data)
+		}
+	}
+}
+
+func TestOpenStore(t *testing.T) {
+	for _, name := range []string{StoreConfig, StoreRefs} {
+		store, err := OpenStore(name)
+		if err != nil {
+			t.Fatalf("OpenStore(%q): %v", name, err)
+		}
+		if store.Name() != name {
+			t.Errorf("OpenStore(%q).Name() = %q", name, store.Name())
+		}
+	}
+	if _, err := OpenStore("sqlite"); err == nil {
+		t.Error("OpenStore should reject unknown stores")
+	}
+}
+
+func TestParseStackKey(t *testing.T) {
+	tests := []struct {
+		key, branch, field string
+		ok                 bool
+	}{
+		{"stack.feat-auth.parent", "feat-auth", "parent", true},
+		{"stack.release.2.x-fix.parent", "release.2.x-fix", "parent", true},
+		{"stack.feat/auth.base", "feat/auth", "base", true},
+		{"stack.Feat/Ünïcode.frozen", "Feat/Ünïcode", "frozen", true},
+		{"stack.x.parent.parent", "x.parent", "parent", true},
+		{`stack.a"b,c{d}#;.parent`, `a"b,c{d}#;`, "parent", true},
+		{"stack.parent", "", "", false},
+		{"st.trunk", "", "", false},
+		{"stacks.feat.parent", "", "", false},
+	}
+	for _, tt := range tests {
+		branch, field, ok := parseStackKey(tt.key)
+		if branch != tt.branch || field != tt.field || ok != tt.ok {
+			t.Errorf("parseStackKey(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.key, branch, field, ok, tt.branch, tt.field, tt.ok)
+		}
+	}
+}
+
+// Older versions wrote the same stack.<branch>.<field> keys; only reading
+// them back split branch names at their dots. What they left loads as is.
+func TestConfigStore_DottedNames(t *testing.T) {
+	r := newTestRepo(t)
+	base := r.tip("main")
+	r.git("branch", "release.2.x-fix")
+	r.git("config", "stack.release.2.x-fix.parent", "main")
+	r.git("config", "stack.release.2.x-fix.base", base)
+	r.git("config", "stack.release.2.x-fix.frozen", "true")
+
+	want := Meta{Parent: "main", Base: base, Frozen: true}
+	metas, err := configStore{}.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(metas, map[string]Meta{"release.2.x-fix": want}) {
+		t.Errorf("Load() = %+v, want only release.2.x-fix with %+v", metas, want)
+	}
+	if meta, ok, err := (configStore{}).Read("release.2.x-fix"); err != nil || !ok || meta != want {
+		t.Errorf("Read() = %+v, %v, %v, want %+v", meta, ok, err, want)
+	}
+}
+
+func TestCurrentStore(t *testing.T) {
+	r := newTestRepo(t)
+	if store, err := CurrentStore(); err != nil || store.Name() != StoreConfig {
+		t.Fatalf("got %v, %v; want the config store by default", store, err)
+	}
+
+	if err := SetCurrentStore(StoreRefs); err != nil {
+		t.Fatal(err)
+	}
+	if store, err := CurrentStore(); err != nil || store.Name() != StoreRefs {
+		t.Errorf("got %v, %v; want the refs store once selected", store, err)
+	}
+	if err := SetCurrentStore("bogus"); err == nil {
+		t.Error("selecting an unknown store should fail")
+	}
+
+	r.git("config", "st.metadata", "bogus")
+	selectedStore.resolved = false
+	if _, err := CurrentStore(); err == nil || !strings.Contains(err.Error(), "unknown metadata store") {
+		t.Errorf("expected an unknown metadata store error, got %v", err)
+	}
+	if _, err := LoadRepo(); err == nil {
+		t.Error("LoadRepo should fail rather than fall back to the config store")
+	}
+}
@@ -0,0 +1,141 @@
+package stack
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+// InferParent returns the most likely parents of an untracked branch: the
+// candidates with the fewest commits of the branch missing from them, and
+// of those, the ones with the fewest commits of their own since they forked.
+// Candidates are the trunks and every tracked branch, plus every other local
+// branch if includeUntracked is set; branches stacked on top of name are
+// never candidates. More than one result means the choice is ambiguous.
+func InferParent(repo *Repo, name string, includeUntracked bool) ([]string, error) {
+	candidates := append([]string{}, repo.Trunks...)
+	for b := range repo.Branches {
+		candidates = append(candidates, b)
+	}
+	if includeUntracked {
+		local, err := git.ListLocalBranches()
+		if err != nil {
+			return nil, fmt.Errorf("could not list branches: %w", err)
+		}
+		for _, b := range local {
+			if _, tracked := repo.Branches[b]; !tracked && !repo.IsTrunk(b) {
+				candidates = append(candidates, b)
+			}
+		}
+	}
+
+	distances := make(map[string]distance)
+	for _, c := range candidates {
+		if c == name {
+			continue
+		}
+		// Skip branches built on top of name. One at the very same commit is
+		// kept only if it is a trunk or already stacked, so adopting a chain of
+		// untracked branches can't form a cycle.
+		if git.IsAncestor(name, c) {
+			_, tracked := repo.Branches[c]
+			sameTip := git.IsAncestor(c, name)
+			if !sameTip || !(tracked || repo.IsTrunk(c)) {
+				continue
+			}
+		}
+		mb, err := git.MergeBase(c, name)
+		if err != nil {
+			continue // unrelated history
+		}
+		missing, err := git.CommitCount(mb, name)
+		if err != nil {
+			continue
+		}
+		extra, err := git.CommitCount(mb, c)
+		if err != nil {
+			continue
+		}
+		distances[c] = distance{missing, extra}
+	}
+
+	best := nearest(distances)
+	if len(best) == 0 {
+		return nil, fmt.Errorf("could not find a parent for %s. Use --parent", name)
+	}
+	return best, nil
+}
+
+// distance measures how far a candidate parent is from a branch.
+type distance struct {
+	missing int // commits on the branch that the candidate lacks
+	extra   int // commits on the candidate since it forked from the branch
+}
+
+func (d distance) less(other distance) bool {
+	if d.missing != other.missing {
+		return d.missing < other.missing
+	}
+	return d.extra < other.extra
+}
+
+// nearest returns the names with the smallest distance, sorted.
+func nearest(distances map[string]distance) []string {
+	var best []string
+	var shortest distance
+	for name, d := range distances {
+		switch {
+		case best == nil || d.less(shortest):
+			shortest = d
+			best = []string{name}
+		case d == shortest:
+			best = append(best, name)
+		}
+	}
+	sort.Strings(best)
+	return best
+}
+
+// ValidateParent checks that the untracked branch name can be tracked on
+// parent: not on itself, nor on a branch stacked on top of it, which would
+// make a cycle.
+func ValidateParent(repo *Repo, name, parent string) error {
+	if parent == name {
+		return fmt.Errorf("cannot track a branch on itself")
+	}
+	seen := make(map[string]bool)
+	for b := repo.Branches[parent]; b != nil && !seen[b.Name]; b = repo.Branches[b.Parent] {
+		seen[b.Name] = true
+		if b.Parent == name {
+			return fmt.Errorf("cannot track %s on %s: %s is stacked on top of it", name, parent, parent)
+		}
+	}
+	return nil
+}
+
+// AdoptBranch tracks an existing branch on parent, recording where it
+// currently forks off as its base.
+func AdoptBranch(name, parent string) error {
+	if err := TrackBranch(name, parent); err != nil {
+		return err
+	}
+	return EnsureBase(name, parent)
+}
+
+// ReleaseBranch untracks a branch without deleting it. Its children are
+// moved onto its parent, keeping its commits as part of their own.
+func ReleaseBranch(repo *Repo, branch *Branch) error {
+	base := ForkPoint(repo, branch)
+	for _, child := range branch.Children {
+		if base != "" {
+			if err := SetBase(child.Name, base); err != nil {
+				return fmt.Errorf("failed to record base of %s: %w", child.Name, err)
+			}
+		}
+		if err := ReparentBranch(child.Name, branch.Parent); err != nil {
+			return fmt.Errorf("failed to reparent %s: %w", child.Name, err)
+		}
+	}
+	return UntrackBranch(branch.Name)
+}
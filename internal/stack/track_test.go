@@ -0,0 +1,59 @@
+package stack
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNearest(t *testing.T) {
+	tests := []struct {
+		name      string
+		distances map[string]distance
+		want      []string
+	}{
+		{"empty", map[string]distance{}, nil},
+		{"single best", map[string]distance{"main": {5, 0}, "feat-a": {2, 0}, "feat-b": {3, 0}}, []string{"feat-a"}},
+		{"tie", map[string]distance{"main": {4, 0}, "feat-b": {1, 0}, "feat-a": {1, 0}}, []string{"feat-a", "feat-b"}},
+		{"sibling loses to trunk", map[string]distance{"main": {1, 0}, "feat-a": {1, 1}}, []string{"main"}},
+		{"zero distance", map[string]distance{"main": {0, 0}}, []string{"main"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nearest(tt.distances); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateParent(t *testing.T) {
+	// vendor is untracked, with a stack built on it
+	repo := makeRepo("main", map[string]string{
+		"ext":      "vendor",
+		"ext-test": "ext",
+		"a":        "main",
+	}, "")
+	BuildTree(repo)
+
+	tests := []struct {
+		name, parent string
+		want         string // substring of the error, or "" for none
+	}{
+		{"vendor", "main", ""},
+		{"vendor", "a", ""},
+		{"spike", "ext-test", ""},
+		{"vendor", "vendor", "on itself"},
+		{"vendor", "ext", "stacked on top"},
+		{"vendor", "ext-test", "stacked on top"},
+	}
+	for _, tt := range tests {
+		err := ValidateParent(repo, tt.name, tt.parent)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("track %s on %s: unexpected error: %v", tt.name, tt.parent, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("track %s on %s: expected error containing %q, got %v", tt.name, tt.parent, tt.want, err)
+		}
+	}
+}
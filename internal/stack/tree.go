@@ -3,8 +3,6 @@ package stack
 import (
 	"fmt"
 	"sort"
-
-	"github.com/rodrigolobo/st/internal/git"
 )
 
 // BuildTree links branches into a tree and identifies stack roots.
@@ -35,8 +33,32 @@ func BuildTree(repo *Repo) {
 	})
 }
 
+// IsTrunk reports whether name is one of the configured trunks.
+func (r *Repo) IsTrunk(name string) bool {
+	for _, t := range r.Trunks {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TrunkOf returns the trunk a branch's stack is built on, or the primary
+// trunk if its stack rests on an untracked branch or its parents loop.
+func (r *Repo) TrunkOf(branch *Branch) string {
+	seen := make(map[string]bool)
+	for b := branch; b != nil && !seen[b.Name]; b = r.Branches[b.Parent] {
+		seen[b.Name] = true
+		if r.IsTrunk(b.Parent) {
+			return b.Parent
+		}
+	}
+	return r.Trunk
+}
+
 // CurrentStack returns the stack (root branch) containing the current branch.
-// Returns nil if the current branch is trunk or untracked.
+// Returns nil if the current branch is trunk or untracked, or if its parents
+// form a cycle (see 'st doctor').
 func CurrentStack(repo *Repo) *Branch {
 	// Find the current branch
 	var currentBranch *Branch
@@ -52,11 +74,16 @@ func CurrentStack(repo *Repo) *Branch {
 
 	// Walk up to find the root
 	b := currentBranch
-	for b.Parent != repo.Trunk {
+	seen := map[string]bool{b.Name: true}
+	for !repo.IsTrunk(b.Parent) {
 		parent, ok := repo.Branches[b.Parent]
 		if !ok {
 			return b
 		}
+		if seen[parent.Name] {
+			return nil
+		}
+		seen[parent.Name] = true
 		b = parent
 	}
 	return b
@@ -72,11 +99,13 @@ func CurrentBranch(repo *Repo) *Branch {
 	return nil
 }
 
-// PathToTrunk returns the list of branches from the given branch down to trunk (exclusive).
+// PathToTrunk returns the list of branches from the given branch down to its trunk (exclusive).
 func PathToTrunk(repo *Repo, branch *Branch) []*Branch {
 	var path []*Branch
+	seen := make(map[string]bool)
 	b := branch
-	for b != nil && b.Name != repo.Trunk {
+	for b != nil && !repo.IsTrunk(b.Name) && !seen[b.Name] {
+		seen[b.Name] = true
 		path = append(path, b)
 		parent, ok := repo.Branches[b.Parent]
 		if !ok {
@@ -91,6 +120,21 @@ func PathToTrunk(repo *Repo, branch *Branch) []*Branch {
 	return path
 }
 
+// ChildrenOf returns the branches stacked directly on name, which may be a
+// tracked branch, a trunk or any other branch stacks are built on.
+func ChildrenOf(repo *Repo, name string) []*Branch {
+	if b, ok := repo.Branches[name]; ok {
+		return b.Children
+	}
+	var children []*Branch
+	for _, root := range repo.Stacks {
+		if root.Parent == name {
+			children = append(children, root)
+		}
+	}
+	return children
+}
+
 // AllBranchesInStack returns all branches in a stack rooted at the given branch (DFS order).
 func AllBranchesInStack(root *Branch) []*Branch {
 	var result []*Branch
@@ -122,19 +166,12 @@ func Leaves(root *Branch) []*Branch {
 }
 
 // NeedsRestack checks if a branch needs to be rebased onto its parent.
-func NeedsRestack(branch *Branch) bool {
+func NeedsRestack(repo *Repo, branch *Branch) bool {
 	if branch.Parent == "" {
 		return false
 	}
-	mb, err := git.MergeBase(branch.Name, branch.Parent)
-	if err != nil {
-		return false
-	}
-	parentTip, err := git.BranchTip(branch.Parent)
-	if err != nil {
-		return false
-	}
-	return mb != parentTip
+	upstream, _, err := planRebase(repo.Snapshot(), branch.Name, branch.Parent, branch.Base, nil)
+	return err == nil && upstream != ""
 }
 
 // NavigateUp moves n branches away from trunk (toward leaves).
@@ -163,7 +200,7 @@ func NavigateDown(repo *Repo, n int) (string, error) {
 
 	target := current
 	for i := 0; i < n; i++ {
-		if target.Parent == repo.Trunk {
+		if repo.IsTrunk(target.Parent) {
 			return "", fmt.Errorf("already at the bottom of the stack")
 		}
 		parent, ok := repo.Branches[target.Parent]
@@ -183,8 +220,10 @@ func NavigateTop(repo *Repo) (string, error) {
 	}
 
 	target := current
-	for len(target.Children) > 0 {
+	seen := map[string]bool{current.Name: true}
+	for len(target.Children) > 0 && !seen[target.Children[0].Name] {
 		target = target.Children[0]
+		seen[target.Name] = true
 	}
 	if target.Name == current.Name {
 		return "", fmt.Errorf("already at the top of the stack")
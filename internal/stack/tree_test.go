@@ -1,6 +1,7 @@
 package stack
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -8,6 +9,7 @@ import (
 func makeRepo(trunk string, branches map[string]string, current string) *Repo {
 	repo := &Repo{
 		Trunk:    trunk,
+		Trunks:   []string{trunk},
 		Branches: make(map[string]*Branch),
 	}
 	for name, parent := range branches {
@@ -378,6 +380,37 @@ func TestAllBranchesInStack_Single(t *testing.T) {
 	}
 }
 
+// --- ChildrenOf ---
+
+func TestChildrenOf(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"a":   "main",
+		"b":   "main",
+		"a1":  "a",
+		"a2":  "a",
+		"ext": "vendor",
+	}, "")
+	BuildTree(repo)
+
+	names := func(branches []*Branch) []string {
+		var out []string
+		for _, b := range branches {
+			out = append(out, b.Name)
+		}
+		return out
+	}
+	for parent, want := range map[string][]string{
+		"a":      {"a1", "a2"},
+		"main":   {"a", "b"},
+		"vendor": {"ext"},
+		"b":      nil,
+	} {
+		if got := names(ChildrenOf(repo, parent)); !reflect.DeepEqual(got, want) {
+			t.Errorf("ChildrenOf(%s) = %v, want %v", parent, got, want)
+		}
+	}
+}
+
 // --- Leaves ---
 
 func TestLeaves_Linear(t *testing.T) {
@@ -763,3 +796,91 @@ func TestNavigateBottom_NotTracked(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+// --- Cycles ---
+
+func TestCurrentStack_CycleReturnsNil(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"a": "b",
+		"b": "a",
+	}, "a")
+	BuildTree(repo)
+
+	if root := CurrentStack(repo); root != nil {
+		t.Errorf("expected nil for a cycle, got %s", root.Name)
+	}
+}
+
+func TestPathToTrunk_CycleTerminates(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"a": "b",
+		"b": "c",
+		"c": "a",
+	}, "")
+	BuildTree(repo)
+
+	if path := PathToTrunk(repo, repo.Branches["a"]); len(path) != 3 {
+		t.Errorf("expected each branch once, got %d", len(path))
+	}
+}
+
+func TestNavigateTop_CycleTerminates(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"a": "b",
+		"b": "a",
+	}, "a")
+	BuildTree(repo)
+
+	if target, err := NavigateTop(repo); err != nil || target != "b" {
+		t.Errorf("expected b, got %q (%v)", target, err)
+	}
+}
+
+// --- Multiple trunks ---
+
+func makeMultiTrunkRepo(current string) *Repo {
+	repo := makeRepo("main", map[string]string{
+		"feat":     "main",
+		"fix":      "release/1.x",
+		"fix-test": "fix",
+		"ext":      "vendor",
+	}, current)
+	repo.Trunks = append(repo.Trunks, "release/1.x")
+	BuildTree(repo)
+	return repo
+}
+
+func TestIsTrunk(t *testing.T) {
+	repo := makeMultiTrunkRepo("")
+	for name, want := range map[string]bool{"main": true, "release/1.x": true, "fix": false, "vendor": false} {
+		if got := repo.IsTrunk(name); got != want {
+			t.Errorf("IsTrunk(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestTrunkOf(t *testing.T) {
+	repo := makeMultiTrunkRepo("")
+	for name, want := range map[string]string{"feat": "main", "fix": "release/1.x", "fix-test": "release/1.x", "ext": "main"} {
+		if got := repo.TrunkOf(repo.Branches[name]); got != want {
+			t.Errorf("TrunkOf(%s) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCurrentStack_SecondTrunk(t *testing.T) {
+	repo := makeMultiTrunkRepo("fix-test")
+	if s := CurrentStack(repo); s == nil || s.Name != "fix" {
+		t.Errorf("expected fix, got %v", s)
+	}
+	if path := PathToTrunk(repo, repo.Branches["fix-test"]); len(path) != 2 || path[0].Name != "fix" {
+		t.Errorf("expected [fix fix-test], got %d branches", len(path))
+	}
+}
+
+func TestNavigateDown_SecondTrunk(t *testing.T) {
+	repo := makeMultiTrunkRepo("fix")
+	if _, err := NavigateDown(repo, 1); err == nil || err.Error() != "already at the bottom of the stack" {
+		t.Errorf("expected to stop above release/1.x, got %v", err)
+	}
+}
@@ -4,13 +4,18 @@ package stack
 type Branch struct {
 	Name     string
 	Parent   string
+	Base     string // parent tip the branch was last built on ("" if unknown)
 	Children []*Branch
 	Current  bool
+	Frozen   bool // never rewritten or pushed by st
 }
 
 // Repo holds the full state of all tracked stacks.
 type Repo struct {
-	Trunk    string
+	Trunk    string             // the primary trunk
+	Trunks   []string           // every trunk, primary first
 	Branches map[string]*Branch // all tracked branches
-	Stacks   []*Branch          // root branches (parent == trunk)
+	Stacks   []*Branch          // root branches (parent == a trunk)
+
+	snapshot *Snapshot // loaded on first use by Snapshot
 }
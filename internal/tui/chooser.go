@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ChooserModel is a bubbletea model for picking one of a few options.
+type ChooserModel struct {
+	title    string
+	options  []string
+	cursor   int
+	chosen   string
+	quitting bool
+}
+
+// NewChooserModel creates a chooser over options with a title line.
+func NewChooserModel(title string, options []string) ChooserModel {
+	return ChooserModel{title: title, options: options}
+}
+
+// Chosen returns the option the user selected, or empty string.
+func (m ChooserModel) Chosen() string {
+	return m.chosen
+}
+
+func (m ChooserModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ChooserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "q", "esc", "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.options)-1 {
+			m.cursor++
+		}
+	case "enter":
+		if len(m.options) > 0 {
+			m.chosen = m.options[m.cursor]
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m ChooserModel) View() string {
+	if m.quitting || m.chosen != "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(HeaderStyle.Render(" "+m.title+" ") + "\n")
+	for i, opt := range m.options {
+		if i == m.cursor {
+			sb.WriteString(SelectedItemStyle.Render("> "+opt) + "\n")
+		} else {
+			sb.WriteString(NormalItemStyle.Render("  "+opt) + "\n")
+		}
+	}
+	help := DimStyle.Render("  ↑↓/jk: navigate • enter: select • q/esc: cancel")
+	return sb.String() + "\n" + help
+}
+
+// MultiChooserModel is a bubbletea model for picking any number of options,
+// all of them selected to begin with.
+type MultiChooserModel struct {
+	title     string
+	options   []string
+	selected  map[int]bool
+	cursor    int
+	confirmed bool
+	quitting  bool
+}
+
+// NewMultiChooserModel creates a multi-chooser over options with a title
+// line.
+func NewMultiChooserModel(title string, options []string) MultiChooserModel {
+	selected := make(map[int]bool, len(options))
+	for i := range options {
+		selected[i] = true
+	}
+	return MultiChooserModel{title: title, options: options, selected: selected}
+}
+
+// Chosen returns the selected options in their original order, and false
+// if the user quit without confirming.
+func (m MultiChooserModel) Chosen() ([]string, bool) {
+	if !m.confirmed {
+		return nil, false
+	}
+	chosen := []string{}
+	for i, opt := range m.options {
+		if m.selected[i] {
+			chosen = append(chosen, opt)
+		}
+	}
+	return chosen, true
+}
+
+func (m MultiChooserModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m MultiChooserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "q", "esc", "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.options)-1 {
+			m.cursor++
+		}
+	case " ", "x":
+		m.selected[m.cursor] = !m.selected[m.cursor]
+	case "enter":
+		m.confirmed = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m MultiChooserModel) View() string {
+	if m.quitting || m.confirmed {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(HeaderStyle.Render(" "+m.title+" ") + "\n")
+	for i, opt := range m.options {
+		box := "[ ] "
+		if m.selected[i] {
+			box = "[x] "
+		}
+		if i == m.cursor {
+			sb.WriteString(SelectedItemStyle.Render("> "+box+opt) + "\n")
+		} else {
+			sb.WriteString(NormalItemStyle.Render("  "+box+opt) + "\n")
+		}
+	}
+	help := DimStyle.Render("  ↑↓/jk: navigate • space: toggle • enter: confirm • q/esc: cancel")
+	return sb.String() + "\n" + help
+}
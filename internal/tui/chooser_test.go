@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestChooserModel_Selects(t *testing.T) {
+	var model tea.Model = NewChooserModel("Parent of feat", []string{"feat-a", "feat-b"})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if got := model.(ChooserModel).Chosen(); got != "feat-b" {
+		t.Errorf("expected feat-b, got %q", got)
+	}
+}
+
+func TestMultiChooserModel_TogglesAndConfirms(t *testing.T) {
+	var model tea.Model = NewMultiChooserModel("Move onto prereq", []string{"feat-a", "feat-b", "feat-c"})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	got, ok := model.(MultiChooserModel).Chosen()
+	if !ok || !reflect.DeepEqual(got, []string{"feat-a", "feat-c"}) {
+		t.Errorf("expected [feat-a feat-c], got %v (confirmed: %v)", got, ok)
+	}
+}
+
+func TestMultiChooserModel_Quit(t *testing.T) {
+	var model tea.Model = NewMultiChooserModel("Move onto prereq", []string{"feat-a"})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if _, ok := model.(MultiChooserModel).Chosen(); ok {
+		t.Error("quitting should not confirm a choice")
+	}
+}
@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+)
+
+// pieceColors tell the branches of a hunk split apart.
+var pieceColors = []lipgloss.Color{Green, Yellow, Cyan, Magenta, Red}
+
+type hunkRef struct {
+	file, unit int
+}
+
+// HunkSplitModel is the bubbletea model for assigning the hunks of a
+// branch's diff to the branches it is being split into.
+type HunkSplitModel struct {
+	branch    string
+	files     []git.FileDiff
+	assign    [][]int // file -> unit -> 1-based branch
+	units     []hunkRef
+	cursor    int
+	confirmed bool
+	quitting  bool
+}
+
+// NewHunkSplitModel creates a hunk picker with every hunk on the first branch.
+func NewHunkSplitModel(branch string, files []git.FileDiff) HunkSplitModel {
+	m := HunkSplitModel{branch: branch, files: files}
+	for i, f := range files {
+		m.assign = append(m.assign, make([]int, f.Units()))
+		for j := range m.assign[i] {
+			m.assign[i][j] = 1
+			m.units = append(m.units, hunkRef{i, j})
+		}
+	}
+	return m
+}
+
+// Assignment returns the branch chosen for each hunk, or nil if the user
+// quit without confirming.
+func (m HunkSplitModel) Assignment() [][]int {
+	if !m.confirmed {
+		return nil
+	}
+	return m.assign
+}
+
+// pieces returns the number of branches hunks are currently spread over.
+func (m HunkSplitModel) pieces() int {
+	n := 0
+	for _, units := range m.assign {
+		for _, p := range units {
+			n = max(n, p)
+		}
+	}
+	return n
+}
+
+// pieceName returns the name the nth branch will get.
+func (m HunkSplitModel) pieceName(n int) string {
+	if n == m.pieces() {
+		return m.branch
+	}
+	return stack.SplitPieceName(m.branch, n)
+}
+
+func (m HunkSplitModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m HunkSplitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || len(m.units) == 0 {
+		return m, nil
+	}
+	ref := m.units[m.cursor]
+
+	switch key.String() {
+	case "q", "esc", "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.units)-1 {
+			m.cursor++
+		}
+
+	case "left", "h":
+		if m.assign[ref.file][ref.unit] > 1 {
+			m.assign[ref.file][ref.unit]--
+		}
+
+	case "right", "l":
+		if m.assign[ref.file][ref.unit] < 9 {
+			m.assign[ref.file][ref.unit]++
+		}
+
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		m.assign[ref.file][ref.unit] = int(key.String()[0] - '0')
+
+	case "f":
+		// Send the whole file where the selected hunk goes
+		for j := range m.assign[ref.file] {
+			m.assign[ref.file][j] = m.assign[ref.file][ref.unit]
+		}
+
+	case "enter":
+		if m.pieces() >= 2 {
+			m.confirmed = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m HunkSplitModel) View() string {
+	if m.quitting || m.confirmed {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(HeaderStyle.Render(" Split "+m.branch+" by hunk ") + "\n")
+
+	idx := 0
+	for i, f := range m.files {
+		sb.WriteString("  " + InfoStyle.Render(f.Path) + "\n")
+		for j := 0; j < f.Units(); j++ {
+			cursor := "  "
+			if idx == m.cursor {
+				cursor = "> "
+			}
+			label := "(whole file)"
+			if len(f.Hunks) > 0 {
+				label = f.Hunks[j].Header + " " + firstChange(f.Hunks[j])
+			}
+			if idx == m.cursor {
+				label = SelectedItemStyle.Render(label)
+			} else {
+				label = DimStyle.Render(label)
+			}
+			sb.WriteString(cursor + "  " + m.pieceStyle(m.assign[i][j]).Render(fmt.Sprintf("[%d]", m.assign[i][j])) + " " + label + "\n")
+			idx++
+		}
+	}
+
+	sb.WriteString("\n")
+	for p := 1; p <= m.pieces(); p++ {
+		sb.WriteString("  " + m.pieceStyle(p).Render(fmt.Sprintf("[%d] %s", p, m.pieceName(p))) + "\n")
+	}
+
+	help := DimStyle.Render("  ↑↓/jk: navigate • 1-9/←→: assign branch • f: whole file • enter: confirm • q/esc: cancel")
+	return sb.String() + "\n" + help
+}
+
+func (m HunkSplitModel) pieceStyle(n int) lipgloss.Style {
+	return lipgloss.NewStyle().Bold(true).Foreground(pieceColors[(n-1)%len(pieceColors)])
+}
+
+// firstChange returns the first added or removed line of a hunk, shortened.
+func firstChange(h git.Hunk) string {
+	for _, l := range h.Lines {
+		if strings.HasPrefix(l, "+") || strings.HasPrefix(l, "-") {
+			if len(l) > 60 {
+				l = l[:60] + "…"
+			}
+			return l
+		}
+	}
+	return ""
+}
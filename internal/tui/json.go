@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"encoding/json"
+
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+)
+
+// JSONVersion is bumped whenever a field is removed or changes meaning.
+// Adding fields does not change it.
+const JSONVersion = 1
+
+// LogJSON is the machine-readable form of 'st log'.
+type LogJSON struct {
+	Version  int          `json:"version"`
+	Trunk    string       `json:"trunk"`  // the primary trunk
+	Trunks   []string     `json:"trunks"` // every trunk, primary first
+	Current  string       `json:"current"`
+	Stacks   []StackJSON  `json:"stacks"`
+	Branches []BranchJSON `json:"branches"`
+}
+
+// StackJSON describes one stack: a root branch and everything above it.
+type StackJSON struct {
+	Root     string   `json:"root"`
+	Parent   string   `json:"parent"`   // the root's parent, usually a trunk
+	Trunk    string   `json:"trunk"`    // the trunk it restacks onto and merges into
+	Branches []string `json:"branches"` // depth-first, root first
+}
+
+// BranchJSON describes a single tracked branch.
+type BranchJSON struct {
+	Name         string      `json:"name"`
+	Parent       string      `json:"parent"`
+	Children     []string    `json:"children"`
+	Commits      int         `json:"commits"`
+	Tip          string      `json:"tip"`
+	NeedsRestack bool        `json:"needs_restack"`
+	Frozen       bool        `json:"frozen"`
+	Current      bool        `json:"current"`
+	Remote       *RemoteJSON `json:"remote"` // null if the branch has no upstream
+}
+
+// RemoteJSON describes a branch's upstream, as of the last fetch.
+type RemoteJSON struct {
+	Upstream string `json:"upstream"`
+	Ahead    int    `json:"ahead"`  // local commits not pushed
+	Behind   int    `json:"behind"` // remote commits not in the local branch
+	Status   string `json:"status"` // up-to-date, ahead, behind, diverged or gone
+}
+
+// RenderTreeJSON renders the full stack tree as indented JSON.
+func RenderTreeJSON(repo *stack.Repo) (string, error) {
+	out := LogJSON{
+		Version:  JSONVersion,
+		Trunk:    repo.Trunk,
+		Trunks:   repo.Trunks,
+		Stacks:   []StackJSON{},
+		Branches: []BranchJSON{},
+	}
+	if current := stack.CurrentBranch(repo); current != nil {
+		out.Current = current.Name
+	}
+
+	ups := upstreams()
+	for _, root := range repo.Stacks {
+		s := StackJSON{Root: root.Name, Parent: root.Parent, Trunk: repo.TrunkOf(root), Branches: []string{}}
+		for _, b := range stack.AllBranchesInStack(root) {
+			s.Branches = append(s.Branches, b.Name)
+			out.Branches = append(out.Branches, branchJSON(b, repo, ups))
+		}
+		out.Stacks = append(out.Stacks, s)
+	}
+
+	return marshalJSON(out)
+}
+
+// RenderBranchJSON renders info about a single branch as indented JSON.
+func RenderBranchJSON(branch *stack.Branch, repo *stack.Repo) (string, error) {
+	out := struct {
+		Version int    `json:"version"`
+		Trunk   string `json:"trunk"`
+		BranchJSON
+	}{
+		Version:    JSONVersion,
+		Trunk:      repo.Trunk,
+		BranchJSON: branchJSON(branch, repo, upstreams()),
+	}
+	return marshalJSON(out)
+}
+
+func branchJSON(branch *stack.Branch, repo *stack.Repo, ups map[string]git.Upstream) BranchJSON {
+	b := BranchJSON{
+		Name:         branch.Name,
+		Parent:       branch.Parent,
+		Children:     []string{},
+		NeedsRestack: stack.NeedsRestack(repo, branch),
+		Frozen:       branch.Frozen,
+		Current:      branch.Current,
+	}
+	for _, c := range branch.Children {
+		b.Children = append(b.Children, c.Name)
+	}
+	if count, err := repo.Snapshot().CommitCount(branch.Parent, branch.Name); err == nil {
+		b.Commits = count
+	}
+	if tip, err := repo.Snapshot().Tip(branch.Name); err == nil {
+		b.Tip = tip
+	}
+	if u, ok := ups[branch.Name]; ok {
+		b.Remote = &RemoteJSON{Upstream: u.Ref, Ahead: u.Ahead, Behind: u.Behind, Status: upstreamState(u)}
+	}
+	return b
+}
+
+// upstreamState summarizes an upstream for RemoteJSON.Status.
+func upstreamState(u git.Upstream) string {
+	switch {
+	case u.Gone:
+		return "gone"
+	case u.Ahead > 0 && u.Behind > 0:
+		return "diverged"
+	case u.Ahead > 0:
+		return "ahead"
+	case u.Behind > 0:
+		return "behind"
+	}
+	return "up-to-date"
+}
+
+func marshalJSON(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rodrigolobo/st/internal/git"
+	"github.com/rodrigolobo/st/internal/stack"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// checkGolden compares got against testdata/<name>.golden, rewriting the
+// file instead when the test is run with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+func TestRenderTreeJSON_Golden(t *testing.T) {
+	tests := []struct {
+		name     string
+		branches map[string]string
+		current  string
+	}{
+		{"log_empty", map[string]string{}, ""},
+		{"log_linear", map[string]string{"root": "main", "child": "root", "grand": "child"}, "child"},
+		{"log_forked", map[string]string{"root": "main", "child-a": "root", "child-b": "root", "other": "main"}, ""},
+		{"log_orphan", map[string]string{"feat-a": "main", "orphan": "external/branch"}, "orphan"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := makeRepo("main", tt.branches, tt.current)
+			out, err := RenderTreeJSON(repo)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			checkGolden(t, tt.name, out)
+		})
+	}
+}
+
+func TestRenderBranchJSON_Golden(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"root":    "main",
+		"child-a": "root",
+		"child-b": "root",
+	}, "root")
+	repo.Branches["root"].Frozen = true
+
+	out, err := RenderBranchJSON(repo.Branches["root"], repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkGolden(t, "branch_with_children", out)
+}
+
+func TestRenderTreeJSON_RemoteGolden(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"pushed":   "main",
+		"diverged": "pushed",
+		"deleted":  "main",
+		"local":    "main",
+	}, "")
+	withUpstreams(t, map[string]git.Upstream{
+		"pushed":   {Ref: "origin/pushed"},
+		"diverged": {Ref: "origin/diverged", Ahead: 2, Behind: 1},
+		"deleted":  {Ref: "origin/deleted", Gone: true},
+	})
+
+	out, err := RenderTreeJSON(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkGolden(t, "log_remote", out)
+}
+
+func TestRenderTreeJSON_RoundTrip(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"root":  "main",
+		"child": "root",
+	}, "child")
+
+	out, err := RenderTreeJSON(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var parsed LogJSON
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if parsed.Version != JSONVersion || parsed.Trunk != "main" || parsed.Current != "child" {
+		t.Errorf("unexpected header: %+v", parsed)
+	}
+	if len(parsed.Branches) != 2 || parsed.Branches[0].Name != "root" || parsed.Branches[0].Children[0] != "child" {
+		t.Errorf("unexpected branches: %+v", parsed.Branches)
+	}
+}
+
+// gitRepo creates a git repository with main as its trunk in a temporary
+// directory and runs the test inside it. The returned function runs git
+// there, failing the test on error.
+func gitRepo(t *testing.T) func(args ...string) string {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Chdir(t.TempDir())
+
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.name", "Test")
+	run("config", "user.email", "test@example.com")
+	run("config", "st.trunk", "main")
+	run("commit", "-q", "--allow-empty", "-m", "init")
+	return run
+}
+
+func TestRenderTreeJSON_RealRepo(t *testing.T) {
+	git := gitRepo(t)
+	git("checkout", "-q", "-b", "root")
+	git("commit", "-q", "--allow-empty", "-m", "root 1")
+	git("commit", "-q", "--allow-empty", "-m", "root 2")
+	git("checkout", "-q", "-b", "child")
+	git("commit", "-q", "--allow-empty", "-m", "child 1")
+	git("checkout", "-q", "root")
+	git("commit", "-q", "--allow-empty", "-m", "root 3")
+	for name, parent := range map[string]string{"root": "main", "child": "root"} {
+		if err := stack.TrackBranch(name, parent); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo, err := stack.LoadRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stack.BuildTree(repo)
+	out, err := RenderTreeJSON(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var parsed LogJSON
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if parsed.Current != "root" || len(parsed.Branches) != 2 {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	want := map[string]struct {
+		commits      int
+		needsRestack bool
+	}{
+		"root":  {3, false},
+		"child": {1, true},
+	}
+	for _, b := range parsed.Branches {
+		w := want[b.Name]
+		if b.Commits != w.commits {
+			t.Errorf("%s: commits = %d, want %d", b.Name, b.Commits, w.commits)
+		}
+		if b.NeedsRestack != w.needsRestack {
+			t.Errorf("%s: needs_restack = %v, want %v", b.Name, b.NeedsRestack, w.needsRestack)
+		}
+		if tip := git("rev-parse", b.Name); b.Tip != tip {
+			t.Errorf("%s: tip = %q, want %s", b.Name, b.Tip, tip)
+		}
+	}
+}
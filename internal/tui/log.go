@@ -8,38 +8,129 @@ import (
 	"github.com/rodrigolobo/st/internal/stack"
 )
 
+// LogFormat selects how much RenderLog shows for each branch.
+type LogFormat int
+
+const (
+	LogNormal LogFormat = iota // tree with commit counts and status
+	LogShort                   // one compact line per branch, no tree lines
+	LogLong                    // tree with each branch's commits listed under it
+)
+
+// LogOptions controls what RenderLog prints.
+type LogOptions struct {
+	Format  LogFormat
+	Stack   bool // only the current stack
+	Reverse bool // leaves at the top and trunk at the bottom
+}
+
+// branchLog lists a branch's commits, newest first. Tests replace it.
+var branchLog = git.ShortLog
+
+// upstreams returns the upstream of every local branch, or nil if there is
+// no remote to compare against. Tests replace it.
+var upstreams = func() map[string]git.Upstream {
+	if !git.HasRemote() {
+		return nil
+	}
+	u, err := git.Upstreams()
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+// remoteStatus describes how a branch compares to its upstream, or returns
+// "" if they match or there is no remote. warn is set when the branch needs
+// attention before it can be pushed normally.
+func remoteStatus(name string, ups map[string]git.Upstream) (status string, warn bool) {
+	if ups == nil {
+		return "", false
+	}
+	u, ok := ups[name]
+	switch {
+	case !ok:
+		return "not pushed", false
+	case u.Gone:
+		return "remote deleted", true
+	case u.Ahead > 0 && u.Behind > 0:
+		return fmt.Sprintf("↑%d ↓%d needs force push", u.Ahead, u.Behind), true
+	case u.Ahead > 0:
+		return fmt.Sprintf("↑%d", u.Ahead), false
+	case u.Behind > 0:
+		return fmt.Sprintf("↓%d", u.Behind), true
+	}
+	return "", false
+}
+
 // RenderTree renders the full stack tree as a string.
 func RenderTree(repo *stack.Repo) string {
-	var sb strings.Builder
+	return RenderLog(repo, LogOptions{})
+}
 
-	// Group roots by parent
+// RenderLog renders the stack tree as a string in the given format. With
+// opts.Stack set and no current stack, it renders nothing.
+func RenderLog(repo *stack.Repo, opts LogOptions) string {
+	roots := repo.Stacks
+	if opts.Stack {
+		roots = nil
+		if root := stack.CurrentStack(repo); root != nil {
+			roots = []*stack.Branch{root}
+		}
+	}
+
+	// Group roots by parent, trunks first in the order they are configured
 	groups := make(map[string][]*stack.Branch)
 	var parentOrder []string
-	for _, root := range repo.Stacks {
-		if _, seen := groups[root.Parent]; !seen {
+	for _, root := range roots {
+		if _, seen := groups[root.Parent]; !seen && !repo.IsTrunk(root.Parent) {
 			parentOrder = append(parentOrder, root.Parent)
 		}
 		groups[root.Parent] = append(groups[root.Parent], root)
 	}
+	var trunks []string
+	for _, trunk := range repo.Trunks {
+		if _, ok := groups[trunk]; ok {
+			trunks = append(trunks, trunk)
+		}
+	}
+	parentOrder = append(trunks, parentOrder...)
 
+	ups := upstreams()
+	var lines []string
 	for gi, parent := range parentOrder {
 		if gi > 0 {
-			sb.WriteString("\n")
+			lines = append(lines, "")
 		}
-		sb.WriteString(TrunkStyle.Render(parent))
-		sb.WriteString("\n")
+		lines = append(lines, TrunkStyle.Render(parent))
 
 		roots := groups[parent]
 		for i, root := range roots {
 			isLast := i == len(roots)-1
-			renderBranch(&sb, root, repo, "", isLast)
+			if opts.Format == LogShort {
+				lines = renderShortBranch(lines, root, repo, 0)
+			} else {
+				lines = renderBranch(lines, root, repo, opts, ups, "", isLast)
+			}
 		}
 	}
+	if len(lines) == 0 {
+		return ""
+	}
 
-	return sb.String()
+	if opts.Reverse {
+		for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+			lines[i], lines[j] = lines[j], lines[i]
+		}
+		// Last children now come first, so their corners open downwards
+		for i, line := range lines {
+			lines[i] = strings.Replace(line, "└── ", "┌── ", 1)
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
 }
 
-func renderBranch(sb *strings.Builder, branch *stack.Branch, repo *stack.Repo, prefix string, isLast bool) {
+func renderBranch(lines []string, branch *stack.Branch, repo *stack.Repo, opts LogOptions, ups map[string]git.Upstream, prefix string, isLast bool) []string {
 	// Draw connector
 	connector := "├── "
 	if isLast {
@@ -56,7 +147,7 @@ func renderBranch(sb *strings.Builder, branch *stack.Branch, repo *stack.Repo, p
 
 	// Commit count
 	commitCount := ""
-	count, err := git.CommitCount(branch.Parent, branch.Name)
+	count, err := repo.Snapshot().CommitCount(branch.Parent, branch.Name)
 	if err == nil {
 		if count == 1 {
 			commitCount = DimStyle.Render("  1 commit")
@@ -65,19 +156,33 @@ func renderBranch(sb *strings.Builder, branch *stack.Branch, repo *stack.Repo, p
 		}
 	}
 
+	// Remote status
+	remoteIndicator := ""
+	if status, warn := remoteStatus(branch.Name, ups); warn {
+		remoteIndicator = WarningStyle.Render("  " + status)
+	} else if status != "" {
+		remoteIndicator = DimStyle.Render("  " + status)
+	}
+
 	// Needs restack indicator
 	restackIndicator := ""
-	if stack.NeedsRestack(branch) {
+	if stack.NeedsRestack(repo, branch) {
 		restackIndicator = WarningStyle.Render("  ⟳ needs restack")
 	}
 
+	// Frozen indicator
+	frozenIndicator := ""
+	if branch.Frozen {
+		frozenIndicator = InfoStyle.Render("  🔒 frozen")
+	}
+
 	// Current marker
 	hereMarker := ""
 	if branch.Current {
 		hereMarker = HereMarker
 	}
 
-	sb.WriteString(prefix + connector + nameStr + commitCount + restackIndicator + hereMarker + "\n")
+	lines = append(lines, prefix+connector+nameStr+commitCount+remoteIndicator+frozenIndicator+restackIndicator+hereMarker)
 
 	// Children
 	childPrefix := prefix
@@ -87,10 +192,60 @@ func renderBranch(sb *strings.Builder, branch *stack.Branch, repo *stack.Repo, p
 		childPrefix += "│   "
 	}
 
+	if opts.Format == LogLong {
+		commitPrefix := childPrefix + "    "
+		if len(branch.Children) > 0 {
+			commitPrefix = childPrefix + "│   "
+		}
+		commits := branchCommits(branch)
+		if opts.Reverse {
+			// Reversing the whole output will put the newest back on top
+			for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+				commits[i], commits[j] = commits[j], commits[i]
+			}
+		}
+		for _, c := range commits {
+			lines = append(lines, commitPrefix+DimStyle.Render(c))
+		}
+	}
+
 	for i, child := range branch.Children {
 		childIsLast := i == len(branch.Children)-1
-		renderBranch(sb, child, repo, childPrefix, childIsLast)
+		lines = renderBranch(lines, child, repo, opts, ups, childPrefix, childIsLast)
 	}
+	return lines
+}
+
+// renderShortBranch renders a branch and its descendants one per line,
+// indented by depth, with single-character status markers.
+func renderShortBranch(lines []string, branch *stack.Branch, repo *stack.Repo, depth int) []string {
+	bullet, name := "○ ", BranchStyle.Render(branch.Name)
+	if branch.Current {
+		bullet, name = "● ", CurrentBranchStyle.Render(branch.Name)
+	}
+	markers := ""
+	if branch.Frozen {
+		markers += InfoStyle.Render(" 🔒")
+	}
+	if stack.NeedsRestack(repo, branch) {
+		markers += WarningStyle.Render(" ⟳")
+	}
+	lines = append(lines, strings.Repeat("  ", depth)+bullet+name+markers)
+
+	for _, child := range branch.Children {
+		lines = renderShortBranch(lines, child, repo, depth+1)
+	}
+	return lines
+}
+
+// branchCommits returns the one-line summaries of a branch's own commits,
+// newest first.
+func branchCommits(branch *stack.Branch) []string {
+	out, err := branchLog(branch.Parent, branch.Name)
+	if err != nil || out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
 }
 
 // RenderBranchInfo renders detailed info about a branch.
@@ -112,13 +267,33 @@ func RenderBranchInfo(branch *stack.Branch, repo *stack.Repo) string {
 	}
 
 	// Commit count
-	count, err := git.CommitCount(branch.Parent, branch.Name)
+	count, err := repo.Snapshot().CommitCount(branch.Parent, branch.Name)
 	if err == nil {
 		sb.WriteString(fmt.Sprintf("Commits: %d\n", count))
 	}
 
+	// Remote status
+	ups := upstreams()
+	if ups != nil {
+		status, warn := remoteStatus(branch.Name, ups)
+		switch {
+		case status == "not pushed":
+			sb.WriteString(fmt.Sprintf("Remote: %s\n", DimStyle.Render(status)))
+		case warn:
+			sb.WriteString(fmt.Sprintf("Remote: %s %s\n", ups[branch.Name].Ref, WarningStyle.Render(status)))
+		case status != "":
+			sb.WriteString(fmt.Sprintf("Remote: %s %s\n", ups[branch.Name].Ref, status))
+		default:
+			sb.WriteString(fmt.Sprintf("Remote: %s %s\n", ups[branch.Name].Ref, DimStyle.Render("(up to date)")))
+		}
+	}
+
+	if branch.Frozen {
+		sb.WriteString(InfoStyle.Render("Frozen: yes (st will not rewrite or push it)") + "\n")
+	}
+
 	// Restack status
-	if stack.NeedsRestack(branch) {
+	if stack.NeedsRestack(repo, branch) {
 		sb.WriteString(WarningStyle.Render("Status: needs restack") + "\n")
 	} else {
 		sb.WriteString(SuccessStyle.Render("Status: up to date") + "\n")
@@ -1,16 +1,34 @@
 package tui
 
 import (
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/rodrigolobo/st/internal/git"
 	"github.com/rodrigolobo/st/internal/stack"
 )
 
+// TestMain hides the remote state of the repo the tests run in, so output
+// doesn't depend on the clone. Tests that need upstreams set them.
+func TestMain(m *testing.M) {
+	upstreams = func() map[string]git.Upstream { return nil }
+	os.Exit(m.Run())
+}
+
+// withUpstreams makes the renderers see ups as the branches' upstreams for
+// the rest of the test.
+func withUpstreams(t *testing.T, ups map[string]git.Upstream) {
+	orig := upstreams
+	upstreams = func() map[string]git.Upstream { return ups }
+	t.Cleanup(func() { upstreams = orig })
+}
+
 // makeRepo builds an in-memory Repo and runs BuildTree on it.
 func makeRepo(trunk string, branches map[string]string, current string) *stack.Repo {
 	repo := &stack.Repo{
 		Trunk:    trunk,
+		Trunks:   []string{trunk},
 		Branches: make(map[string]*stack.Branch),
 	}
 	for name, parent := range branches {
@@ -97,6 +115,26 @@ func TestRenderTree_MultipleUntrackedParents(t *testing.T) {
 	}
 }
 
+func TestRenderTree_TrunksFirst(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"a-ext":   "a/external",
+		"feat":    "main",
+		"fix-1.x": "release/1.x",
+	}, "")
+	repo.Trunks = append(repo.Trunks, "release/1.x")
+
+	out := RenderTree(repo)
+	main := strings.Index(out, "main\n")
+	release := strings.Index(out, "release/1.x\n")
+	external := strings.Index(out, "a/external\n")
+	if main < 0 || release < 0 || external < 0 {
+		t.Fatalf("expected a header for each group, got:\n%s", out)
+	}
+	if !(main < release && release < external) {
+		t.Errorf("expected trunks in configured order before other parents, got:\n%s", out)
+	}
+}
+
 func TestRenderTree_CurrentBranchMarker(t *testing.T) {
 	repo := makeRepo("main", map[string]string{
 		"feat-a": "main",
@@ -119,6 +157,25 @@ func TestRenderTree_NoCurrent_NoMarker(t *testing.T) {
 	}
 }
 
+func TestRenderTree_FrozenMarker(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"theirs": "main",
+		"ours":   "theirs",
+	}, "ours")
+	repo.Branches["theirs"].Frozen = true
+
+	out := RenderTree(repo)
+	for _, line := range strings.Split(out, "\n") {
+		frozen := strings.Contains(line, "🔒 frozen")
+		if strings.Contains(line, "theirs") && !frozen {
+			t.Errorf("frozen branch should have a lock marker: %q", line)
+		}
+		if strings.Contains(line, "ours") && frozen {
+			t.Errorf("unfrozen branch should not have a lock marker: %q", line)
+		}
+	}
+}
+
 func TestRenderTree_NestedChildren(t *testing.T) {
 	repo := makeRepo("main", map[string]string{
 		"root":  "main",
@@ -262,3 +319,174 @@ func TestRenderBranchInfo_OrphanParent(t *testing.T) {
 		t.Error("output should show untracked parent name")
 	}
 }
+
+func TestRenderLog_Short(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"root":  "main",
+		"child": "root",
+	}, "child")
+
+	out := RenderLog(repo, LogOptions{Format: LogShort})
+	want := "main\n○ root\n  ● child\n"
+	if out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+	if strings.Contains(out, "commit") || strings.Contains(out, "└──") {
+		t.Error("short output should have no commit counts or tree lines")
+	}
+}
+
+func TestRenderLog_Long(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"root":  "main",
+		"child": "root",
+	}, "")
+	commits := map[string]string{
+		"root":  "bbb222 second\naaa111 first",
+		"child": "ccc333 third",
+	}
+	defer func(orig func(string, string) (string, error)) { branchLog = orig }(branchLog)
+	branchLog = func(parent, branch string) (string, error) { return commits[branch], nil }
+
+	out := RenderLog(repo, LogOptions{Format: LogLong})
+	want := []string{
+		"main",
+		"└── root",
+		"    │   bbb222 second",
+		"    │   aaa111 first",
+		"    └── child",
+		"            ccc333 third",
+	}
+	if out != strings.Join(want, "\n")+"\n" {
+		t.Errorf("got:\n%s\nwant:\n%s", out, strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderLog_LongReverse(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"root": "main",
+	}, "")
+	defer func(orig func(string, string) (string, error)) { branchLog = orig }(branchLog)
+	branchLog = func(parent, branch string) (string, error) { return "bbb222 second\naaa111 first", nil }
+
+	out := RenderLog(repo, LogOptions{Format: LogLong, Reverse: true})
+	want := "        bbb222 second\n        aaa111 first\n┌── root\nmain\n"
+	if out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestRenderLog_Reverse(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"a":  "main",
+		"b":  "a",
+		"a2": "a",
+	}, "")
+
+	out := RenderLog(repo, LogOptions{Reverse: true})
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if lines[len(lines)-1] != "main" {
+		t.Errorf("trunk should be last, got %q", lines[len(lines)-1])
+	}
+	pos := func(name string) int {
+		for i, line := range lines {
+			if strings.HasSuffix(strings.TrimSpace(line), " "+name) {
+				return i
+			}
+		}
+		t.Fatalf("%s not found in:\n%s", name, out)
+		return -1
+	}
+	if !(pos("b") < pos("a") && pos("a2") < pos("a")) {
+		t.Errorf("children should be above their parent:\n%s", out)
+	}
+	if strings.Contains(out, "└") {
+		t.Errorf("reversed output should use ┌ corners:\n%s", out)
+	}
+}
+
+func TestRenderLog_Stack(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"mine":       "main",
+		"mine-child": "mine",
+		"other":      "main",
+	}, "mine-child")
+
+	out := RenderLog(repo, LogOptions{Stack: true})
+	if !strings.Contains(out, "mine-child") || !strings.Contains(out, "mine") {
+		t.Errorf("output should contain the current stack:\n%s", out)
+	}
+	if strings.Contains(out, "other") {
+		t.Errorf("output should not contain other stacks:\n%s", out)
+	}
+
+	repo = makeRepo("main", map[string]string{"other": "main"}, "")
+	if out := RenderLog(repo, LogOptions{Stack: true}); out != "" {
+		t.Errorf("expected no output without a current stack, got %q", out)
+	}
+}
+
+func TestRenderTree_RemoteStatus(t *testing.T) {
+	repo := makeRepo("main", map[string]string{
+		"synced":   "main",
+		"ahead":    "main",
+		"behind":   "main",
+		"diverged": "main",
+		"deleted":  "main",
+		"local":    "main",
+	}, "")
+	withUpstreams(t, map[string]git.Upstream{
+		"synced":   {Ref: "origin/synced"},
+		"ahead":    {Ref: "origin/ahead", Ahead: 2},
+		"behind":   {Ref: "origin/behind", Behind: 1},
+		"diverged": {Ref: "origin/diverged", Ahead: 3, Behind: 2},
+		"deleted":  {Ref: "origin/deleted", Gone: true},
+	})
+
+	want := map[string]string{
+		"synced":   "── synced",
+		"ahead":    "── ahead  ↑2",
+		"behind":   "── behind  ↓1",
+		"diverged": "── diverged  ↑3 ↓2 needs force push",
+		"deleted":  "── deleted  remote deleted",
+		"local":    "── local  not pushed",
+	}
+	out := RenderTree(repo)
+	lines := strings.Split(out, "\n")
+	for name, suffix := range want {
+		found := false
+		for _, line := range lines {
+			if strings.HasSuffix(line, suffix) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a line ending in %q for %s:\n%s", suffix, name, out)
+		}
+	}
+}
+
+func TestRenderTree_NoRemote(t *testing.T) {
+	repo := makeRepo("main", map[string]string{"feat-a": "main"}, "")
+	withUpstreams(t, nil)
+
+	if out := RenderTree(repo); strings.Contains(out, "not pushed") {
+		t.Errorf("without a remote, branches should not be marked as not pushed:\n%s", out)
+	}
+}
+
+func TestRenderBranchInfo_Remote(t *testing.T) {
+	repo := makeRepo("main", map[string]string{"feat-a": "main", "feat-b": "main"}, "")
+	withUpstreams(t, map[string]git.Upstream{
+		"feat-a": {Ref: "origin/feat-a", Ahead: 1, Behind: 1},
+	})
+
+	out := RenderBranchInfo(repo.Branches["feat-a"], repo)
+	if !strings.Contains(out, "Remote: origin/feat-a ↑1 ↓1 needs force push") {
+		t.Errorf("output should show the diverged upstream:\n%s", out)
+	}
+	out = RenderBranchInfo(repo.Branches["feat-b"], repo)
+	if !strings.Contains(out, "Remote: not pushed") {
+		t.Errorf("output should show the branch is not pushed:\n%s", out)
+	}
+}
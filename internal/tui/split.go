@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rodrigolobo/st/internal/stack"
+)
+
+// SplitCommit is a commit offered by the split picker.
+type SplitCommit struct {
+	SHA     string
+	Subject string
+}
+
+// ParseShortLog turns 'git log --oneline' output (newest first) into
+// commits, oldest first.
+func ParseShortLog(out string) []SplitCommit {
+	var commits []SplitCommit
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		sha, subject, _ := strings.Cut(line, " ")
+		commits = append([]SplitCommit{{SHA: sha, Subject: subject}}, commits...)
+	}
+	return commits
+}
+
+// SplitModel is the bubbletea model for picking split points in a branch.
+type SplitModel struct {
+	branch    string
+	commits   []SplitCommit // oldest first
+	splits    map[int]bool  // commit index -> a new branch ends here
+	cursor    int
+	confirmed bool
+	quitting  bool
+}
+
+// NewSplitModel creates a split picker for branch's commits, oldest first.
+func NewSplitModel(branch string, commits []SplitCommit) SplitModel {
+	return SplitModel{
+		branch:  branch,
+		commits: commits,
+		splits:  make(map[int]bool),
+	}
+}
+
+// Points returns the SHAs of the commits chosen as split points, oldest
+// first, or nil if the user quit without confirming.
+func (m SplitModel) Points() []string {
+	if !m.confirmed {
+		return nil
+	}
+	var points []string
+	for i, c := range m.commits {
+		if m.splits[i] {
+			points = append(points, c.SHA)
+		}
+	}
+	return points
+}
+
+func (m SplitModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m SplitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "q", "esc", "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.commits)-1 {
+			m.cursor++
+		}
+
+	case " ", "x":
+		// The last commit always ends the original branch
+		if m.cursor < len(m.commits)-1 {
+			m.splits[m.cursor] = !m.splits[m.cursor]
+		}
+
+	case "enter":
+		// Nothing to do until at least one split point is chosen
+		for _, on := range m.splits {
+			if on {
+				m.confirmed = true
+				return m, tea.Quit
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m SplitModel) View() string {
+	if m.quitting || m.confirmed {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(HeaderStyle.Render(" Split "+m.branch+" ") + "\n")
+
+	piece := 1
+	for i, c := range m.commits {
+		cursor := "  "
+		subject := NormalItemStyle.Render(c.Subject)
+		if i == m.cursor {
+			cursor = "> "
+			subject = SelectedItemStyle.Render(c.Subject)
+		}
+		sb.WriteString(cursor + DimStyle.Render(c.SHA) + " " + subject + "\n")
+
+		if m.splits[i] {
+			sb.WriteString(InfoStyle.Render("  ── "+stack.SplitPieceName(m.branch, piece)+" ──") + "\n")
+			piece++
+		}
+	}
+	sb.WriteString(CurrentBranchStyle.Render("  ── "+m.branch+" ──") + "\n")
+
+	help := DimStyle.Render("  ↑↓/jk: navigate • space: split after commit • enter: confirm • q/esc: cancel")
+	return sb.String() + "\n" + help
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rodrigolobo/st/internal/git"
+)
+
+func TestParseShortLog_OldestFirst(t *testing.T) {
+	commits := ParseShortLog("ccc third\nbbb second one\naaa first\n")
+
+	want := []SplitCommit{{"aaa", "first"}, {"bbb", "second one"}, {"ccc", "third"}}
+	if !reflect.DeepEqual(commits, want) {
+		t.Errorf("got %+v, want %+v", commits, want)
+	}
+}
+
+func press(m SplitModel, keys ...string) SplitModel {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		model, _ := m.Update(msg)
+		m = model.(SplitModel)
+	}
+	return m
+}
+
+func TestSplitModel_PicksPoints(t *testing.T) {
+	m := NewSplitModel("feat", ParseShortLog("ccc third\nbbb second\naaa first"))
+
+	m = press(m, " ", "down", " ", "enter")
+	if got, want := m.Points(), []string{"aaa", "bbb"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSplitModel_LastCommitCannotSplit(t *testing.T) {
+	m := NewSplitModel("feat", ParseShortLog("bbb second\naaa first"))
+
+	m = press(m, "down", " ", "enter")
+	if m.Points() != nil {
+		t.Errorf("expected no points, got %v", m.Points())
+	}
+	if !strings.Contains(m.View(), "feat") {
+		t.Error("picker should still be showing")
+	}
+}
+
+func TestSplitModel_ViewNamesPieces(t *testing.T) {
+	m := NewSplitModel("feat", ParseShortLog("ccc third\nbbb second\naaa first"))
+
+	m = press(m, " ")
+	out := m.View()
+	if !strings.Contains(out, "feat-part1") {
+		t.Error("view should name the new branch")
+	}
+}
+
+func TestHunkSplitModel_Assigns(t *testing.T) {
+	files := []git.FileDiff{
+		{Path: "a.txt", Hunks: []git.Hunk{{Header: "@@ -1 +1 @@"}, {Header: "@@ -9 +9 @@"}}},
+		{Path: "img.png"},
+	}
+	m := NewHunkSplitModel("feat", files)
+
+	var model tea.Model = m
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("2")},
+		{Type: tea.KeyDown},
+		{Type: tea.KeyDown},
+		{Type: tea.KeyRight},
+		{Type: tea.KeyEnter},
+	} {
+		model, _ = model.Update(msg)
+	}
+
+	got := model.(HunkSplitModel).Assignment()
+	if want := [][]int{{2, 1}, {2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestHunkSplitModel_NeedsTwoBranches(t *testing.T) {
+	m := NewHunkSplitModel("feat", []git.FileDiff{{Path: "a.txt"}})
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.(HunkSplitModel).Assignment() != nil {
+		t.Error("should not confirm with every hunk on one branch")
+	}
+}
@@ -279,9 +279,9 @@ func renderSwitcherTree(sb *strings.Builder, branch *stack.Branch, repo *stack.R
 		connector = "└── "
 	}
 
-	// For root, show trunk first
+	// For root, show the branch it is built on first
 	if prefix == "" {
-		nameStr := TrunkStyle.Render(repo.Trunk)
+		nameStr := TrunkStyle.Render(branch.Parent)
 		sb.WriteString("  " + nameStr + "\n")
 		prefix = "  "
 	}
@@ -301,8 +301,14 @@ func renderSwitcherTree(sb *strings.Builder, branch *stack.Branch, repo *stack.R
 	}
 
 	marker := ""
+	if branch.Frozen {
+		marker += InfoStyle.Render(" 🔒")
+	}
+	if stack.NeedsRestack(repo, branch) {
+		marker += WarningStyle.Render(" ⟳")
+	}
 	if branch.Current {
-		marker = DimStyle.Render(" ← here")
+		marker += DimStyle.Render(" ← here")
 	}
 
 	cursor := "  "